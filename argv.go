@@ -1,40 +1,129 @@
 package gonfig
 
 import (
-	"flag"
-	"log"
 	"os"
+	"strconv"
 	"strings"
+	"sync/atomic"
 )
 
 // the Argv configurable.
+// Parses command-line arguments of the forms --key=value, --key value, -k value and --flag,
+// which sets flag to "true", and --no-flag which sets flag to "false". Single and double dashes are
+// equivalent like with the flag package. Repeated flags are joined into a comma separated list,
+// --no-flag replaces the earlier values of flag and a flag after it replaces the "false", arguments that are not flags are positional and "--" ends the flags, all arguments after it are positional.
+// Nested keys are given with the ":" separator, --db:host=localhost sets "db:host".
 type ArgvConfig struct {
 	Configurable
 	Prefix string
+	// The arguments to parse, os.Args[1:] is parsed if Args is nil
+	Args []string
+	// command-line flag names of the loaded keys
+	names MemoryConfig
+	// the positional arguments of the last Load
+	positional atomic.Pointer[[]string]
 }
 
-// Creates a new ArgvConfig and returns it as a ReadableConfig
-func NewArgvConfig(prefix string) ReadableConfig {
-	cfg := &ArgvConfig{NewMemoryConfig(), prefix}
+// Creates a new ArgvConfig and returns it as a ReadableConfig.
+// The arguments to parse can be given, if none are given os.Args[1:] is parsed when the config is loaded.
+func NewArgvConfig(prefix string, args ...string) ReadableConfig {
+	cfg := &ArgvConfig{Configurable: NewMemoryConfig(), Prefix: prefix}
+	if len(args) > 0 {
+		cfg.Args = args
+	}
 	return cfg
 }
 
 // Loads all the variables from argv to the underlaying Configurable.
-// If a Prefix is provided for ArgvConfig then keys are imported with the Prefix removed
-// so --test.asd=1 with Prefix 'test.' imports "asd" with value of 1
+// If a Prefix is provided for ArgvConfig then only flags starting with Prefix are imported
+// with the Prefix removed, so --test.asd=1 with Prefix 'test.' imports "asd" with value of 1
 func (self *ArgvConfig) Load() (err error) {
-	flagset := flag.NewFlagSet("arguments", flag.ContinueOnError)
-	flagset.Parse(os.Args)
-
-	flagset.VisitAll(func(f *flag.Flag) {
-		name := f.Name
-		log.Println(f.Name)
-		if self.Prefix != "" && strings.HasPrefix(f.Name, self.Prefix) {
-			name = strings.Replace(name, self.Prefix, "", 1)
+	args := self.Args
+	if args == nil && len(os.Args) > 0 {
+		args = os.Args[1:]
+	}
+	values := make(map[string]string)
+	names := make(map[string]string)
+	positional := []string{}
+	// keys set by a --no- flag, which replaces the value instead of joining it
+	negated := make(map[string]bool)
+	set := func(flag, name, value string, negate bool) {
+		if self.Prefix != "" {
+			if !strings.HasPrefix(name, self.Prefix) {
+				return
+			}
+			name = strings.TrimPrefix(name, self.Prefix)
 		}
-		if getter, ok := f.Value.(flag.Getter); ok {
-			self.Set(name, getter.Get().(string))
+		if previous, ok := values[name]; ok && !negate && !negated[name] {
+			value = previous + "," + value
 		}
-	})
+		values[name] = value
+		names[name] = flag
+		negated[name] = negate
+	}
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			positional = append(positional, args[i+1:]...)
+			break
+		}
+		if !isFlag(arg) {
+			positional = append(positional, arg)
+			continue
+		}
+		flag := strings.TrimLeft(arg, "-")
+		dashes := arg[:len(arg)-len(flag)]
+		if name, value, ok := strings.Cut(flag, "="); ok {
+			set(dashes+name, name, value, false)
+			continue
+		}
+		if strings.HasPrefix(flag, "no-") {
+			set(arg, strings.TrimPrefix(flag, "no-"), "false", true)
+			continue
+		}
+		if i+1 < len(args) && (!isFlag(args[i+1]) || isNumber(args[i+1])) {
+			i++
+			set(arg, flag, args[i], false)
+			continue
+		}
+		set(arg, flag, "true", false)
+	}
+	self.positional.Store(&positional)
+	self.names.Reset(names)
+	self.Configurable.Reset(values)
 	return nil
 }
+
+// Returns the positional arguments of the last Load, the arguments that are not flags or their values
+func (self *ArgvConfig) Positional() []string {
+	if positional := self.positional.Load(); positional != nil {
+		return append([]string{}, *positional...)
+	}
+	return nil
+}
+
+// Lookup key from the underlaying Configurable
+func (self *ArgvConfig) Lookup(key string) (string, bool) {
+	return LookupConfig(self.Configurable, key)
+}
+
+// Observe changes to the underlaying Configurable
+func (self *ArgvConfig) Observe(fn func()) func() {
+	return ObserveConfig(self.Configurable, fn)
+}
+
+// Returns the command-line flag key was loaded from
+func (self *ArgvConfig) Origin(key string) string {
+	return self.names.Get(key)
+}
+
+// reports whether arg is a flag, a single "-" is positional
+func isFlag(arg string) bool {
+	return len(arg) > 1 && arg[0] == '-' && strings.TrimLeft(arg, "-") != ""
+}
+
+// reports whether arg is a number, so negative numbers can be used as flag values
+func isNumber(arg string) bool {
+	_, err := strconv.ParseFloat(arg, 64)
+	return err == nil
+}
@@ -12,13 +12,62 @@ var _ = Describe("ArgvConfig", func() {
 		cfg ReadableConfig
 	)
 	BeforeEach(func() {
-		cfg = NewArgvConfig("test")
+		cfg = NewArgvConfig("", "--name=value", "--port", "8080", "-v", "--no-color", "--tag", "a", "--tag=b", "--db:host", "localhost", "--offset", "-5", "file", "--", "--rest")
 		err = cfg.Load()
 	})
 	It("Should load variables from commandline", func() {
-		Expect(len(cfg.All()) >= 0).To(BeTrue())
-		cfg2 := NewArgvConfig("")
-		cfg2.Load()
-		Expect(len(cfg2.All()) >= len(cfg.All())).To(BeTrue())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(len(NewArgvConfig("").All()) >= 0).To(BeTrue())
+	})
+	It("Should parse --key=value and --key value", func() {
+		Expect(cfg.Get("name")).To(Equal("value"))
+		Expect(cfg.Get("port")).To(Equal("8080"))
+		Expect(cfg.Get("offset")).To(Equal("-5"))
+	})
+	It("Should parse flags without values as booleans", func() {
+		Expect(cfg.Get("v")).To(Equal("true"))
+		Expect(cfg.Get("color")).To(Equal("false"))
+	})
+	It("Should join repeated flags into a list", func() {
+		Expect(cfg.Get("tag")).To(Equal("a,b"))
+	})
+	It("Should join repeated flags regardless of their dashes", func() {
+		cfg = NewArgvConfig("", "--tag=a", "-tag=b", "--tag", "c", "-tag")
+		Expect(cfg.Load()).To(Succeed())
+		Expect(cfg.Get("tag")).To(Equal("a,b,c,true"))
+		Expect(cfg.(*ArgvConfig).Origin("tag")).To(Equal("-tag"))
+	})
+	It("Should replace the values of repeated flags with --no-", func() {
+		cfg = NewArgvConfig("", "--color=auto", "--no-color", "--v", "--no-v", "--v")
+		Expect(cfg.Load()).To(Succeed())
+		Expect(cfg.Get("color")).To(Equal("false"))
+		Expect(cfg.Get("v")).To(Equal("true"))
+	})
+	It("Should load nested keys", func() {
+		Expect(cfg.Get("db:host")).To(Equal("localhost"))
+	})
+	It("Should stop parsing flags at --", func() {
+		_, ok := cfg.(*ArgvConfig).Lookup("rest")
+		Expect(ok).To(BeFalse())
+		Expect(cfg.(*ArgvConfig).Positional()).To(Equal([]string{"file", "--rest"}))
+		Expect(cfg.All()).To(HaveLen(7))
+	})
+	It("Should return the flag a key was loaded from as its origin", func() {
+		Expect(cfg.(*ArgvConfig).Origin("port")).To(Equal("--port"))
+		Expect(cfg.(*ArgvConfig).Origin("v")).To(Equal("-v"))
+		Expect(cfg.(*ArgvConfig).Origin("color")).To(Equal("--no-color"))
+	})
+	It("Should import only flags with the Prefix and remove it", func() {
+		cfg = NewArgvConfig("test.", "--test.asd=1", "--other=2", "-test.v")
+		Expect(cfg.Load()).To(Succeed())
+		Expect(cfg.All()).To(Equal(map[string]string{"asd": "1", "v": "true"}))
+		Expect(cfg.(*ArgvConfig).Origin("asd")).To(Equal("--test.asd"))
+	})
+	It("Should replace the values on reload", func() {
+		argv := cfg.(*ArgvConfig)
+		argv.Args = []string{"--name=other"}
+		Expect(argv.Load()).To(Succeed())
+		Expect(argv.All()).To(Equal(map[string]string{"name": "other"}))
+		Expect(argv.Positional()).To(BeEmpty())
 	})
 })
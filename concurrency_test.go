@@ -0,0 +1,97 @@
+package gonfig_test
+
+import (
+	"fmt"
+	. "github.com/Nomon/gonfig"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"io/ioutil"
+	"os"
+	"sync"
+)
+
+// run with go test -race to detect data races between the goroutines
+var _ = Describe("Concurrent use", func() {
+	const iterations = 200
+	var (
+		cfg  *Gonfig
+		wg   sync.WaitGroup
+		path = "./config_concurrent.json"
+	)
+	run := func(fn func(i int)) {
+		wg.Add(1)
+		go func() {
+			defer GinkgoRecover()
+			defer wg.Done()
+			for i := 0; i < iterations; i++ {
+				fn(i)
+			}
+		}()
+	}
+	BeforeEach(func() {
+		Expect(ioutil.WriteFile(path, []byte(`{"key":"json","nested":{"key":"value"}}`), 0600)).To(Succeed())
+		cfg = NewConfig(nil)
+		cfg.Use("json", NewJsonConfig(path))
+		cfg.Use("mem", NewMemoryConfig())
+		cfg.Defaults.Set("default", "value")
+	})
+	AfterEach(func() {
+		os.Remove(path)
+	})
+
+	It("Should be safe to Get while other goroutines Load, Reset and Set", func() {
+		run(func(i int) {
+			Expect(cfg.Get("default")).To(Equal("value"))
+			cfg.Get("key")
+			cfg.Lookup("nested:key")
+			cfg.All()
+			cfg.Explain("key")
+		})
+		run(func(i int) {
+			Expect(cfg.Load()).To(Succeed())
+		})
+		run(func(i int) {
+			cfg.Use("mem").Set(fmt.Sprint("key", i%10), "mem")
+			cfg.Set("override", fmt.Sprint(i))
+		})
+		run(func(i int) {
+			cfg.Use("mem").Reset(map[string]string{"key": "reset"})
+		})
+		wg.Wait()
+		Expect(cfg.Get("key")).To(Equal("json"))
+		Expect(cfg.Get("override")).To(Equal(fmt.Sprint(iterations - 1)))
+	})
+
+	It("Should be safe to Use while other goroutines Get", func() {
+		run(func(i int) {
+			cfg.Get("key")
+			cfg.Mounts()
+			cfg.Err()
+		})
+		run(func(i int) {
+			name := fmt.Sprint("mount", i%5)
+			cfg.UsePriority(name, i%3, NewMemoryConfig())
+			cfg.SetOptional(name, i%2 == 0)
+		})
+		run(func(i int) {
+			cfg.UseBefore("before", "json", NewMemoryConfig()).Set("key", "before")
+		})
+		wg.Wait()
+		Expect(cfg.Mounts()).To(HaveLen(8))
+		Expect(cfg.Get("key")).To(Equal("before"))
+	})
+
+	It("Should be safe to use MemoryConfig concurrently", func() {
+		mem := NewMemoryConfig()
+		for g := 0; g < 4; g++ {
+			g := g
+			run(func(i int) {
+				mem.Set(fmt.Sprint(g, ":", i), "value")
+				mem.Get(fmt.Sprint(g, ":", i))
+				mem.All()
+			})
+		}
+		wg.Wait()
+		Expect(mem.All()).To(HaveLen(4 * iterations))
+	})
+})
@@ -0,0 +1,344 @@
+package gonfig
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DotenvConfig is a WritableConfig backed by a .env file of NAME=value lines.
+// Lines may start with export, # starts a comment, single quoted values are taken literally
+// and double quoted values support \n, \r, \t, \", \\ and \$ escapes, both may span multiple lines.
+// ${NAME}, ${NAME:-default} and $NAME in unquoted and double quoted values are expanded from the
+// entries earlier in the file and the process environment.
+// Like EnvConfig only variables starting with Prefix are imported, with Prefix removed from their name.
+type DotenvConfig struct {
+	FileConfig
+	Prefix string
+	// Separator in variable names that is mapped to the ":" key separator like with EnvConfig,
+	// with a Separator of "__" APP_DB__HOST is found from "DB:HOST" and saved back as APP_DB__HOST
+	Separator string
+}
+
+// Returns a new WritableConfig backed by a .env file at path, prefix is removed from the imported names.
+// The file does not need to exist, if it does not exist the first Save call will create it.
+// Errors loading the file are not returned, use OpenDotenvConfig or the error from Load
+// or Gonfig.Use to detect a missing or invalid file.
+func NewDotenvConfig(path, prefix string, cfg ...Configurable) WritableConfig {
+	conf, _ := OpenDotenvConfig(path, prefix, cfg...)
+	return conf
+}
+
+// Returns a new WritableConfig backed by a .env file at path like NewDotenvConfig that maps separator
+// in the variable names to the ":" key separator, so nested keys can be loaded and saved.
+func NewMappedDotenvConfig(path, prefix, separator string, cfg ...Configurable) WritableConfig {
+	conf := &DotenvConfig{FileConfig: openFile(path, cfg), Prefix: prefix, Separator: separator}
+	LoadConfig(conf)
+	return conf
+}
+
+// Returns a new WritableConfig backed by a .env file at path like NewDotenvConfig,
+// along with the error from loading the file.
+func OpenDotenvConfig(path, prefix string, cfg ...Configurable) (WritableConfig, error) {
+	conf := &DotenvConfig{FileConfig: openFile(path, cfg), Prefix: prefix}
+	return conf, LoadConfig(conf)
+}
+
+// Attempts to load the .env file at DotenvConfig.Path
+// and Set them into the underlaying Configurable
+func (self *DotenvConfig) Load() error {
+	data, err := ioutil.ReadFile(self.Path)
+	if err != nil {
+		return err
+	}
+	entries, err := unmarshalDotenv(data, true)
+	if err != nil {
+		return err
+	}
+	out := make(map[string]string, len(entries))
+	for name, value := range entries {
+		key, ok := envKey(self.Prefix, name)
+		if !ok || key == "" {
+			continue
+		}
+		if self.Separator != "" {
+			key = strings.Replace(key, self.Separator, ":", -1)
+		}
+		out[key] = value
+	}
+	self.Configurable.Reset(out)
+	return nil
+}
+
+// Attempts to save the configuration from the underlaying Configurable to the .env file at DotenvConfig.Path,
+// values are written double quoted with Prefix prepended to their names and ":" in the keys replaced by Separator.
+// Keys that would not load back as the same variable name, like nested keys without a Separator, fail the Save.
+func (self *DotenvConfig) Save() error {
+	data := self.Configurable.All()
+	keys := make([]string, 0, len(data))
+	for key := range data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var buffer bytes.Buffer
+	for _, key := range keys {
+		name := key
+		if self.Separator != "" {
+			name = strings.Replace(name, ":", self.Separator, -1)
+		}
+		name = self.Prefix + name
+		if !validDotenvName(name) {
+			return fmt.Errorf("dotenv: key %q can not be saved as variable %q", key, name)
+		}
+		buffer.WriteString(name + "=" + quoteDotenv(data[key]) + "\n")
+	}
+	return writeFile(self.Path, buffer.Bytes(), 0)
+}
+
+// Reloads the config whenever the .env file changes, see Watcher
+func (self *DotenvConfig) Watch(interval time.Duration) *Watcher {
+	return watchFile(self, self.Path, interval)
+}
+
+// parses .env data into a map of variable names to values, references to variables that are
+// not defined earlier in data are expanded from the process environment if environ is true
+// and to "" otherwise
+func unmarshalDotenv(data []byte, environ bool) (map[string]string, error) {
+	p := &dotenvParser{input: strings.Replace(string(data), "\r\n", "\n", -1), line: 1}
+	entries := make(map[string]string)
+	lookup := func(name string) (string, bool) {
+		if value, ok := entries[name]; ok {
+			return value, true
+		}
+		if !environ {
+			return "", false
+		}
+		return os.LookupEnv(name)
+	}
+	for {
+		p.skip(" \t\n")
+		if p.done() {
+			return entries, nil
+		}
+		if p.peek() == '#' {
+			p.skipLine()
+			continue
+		}
+		name := p.name(false)
+		if name == "export" && !p.done() && (p.peek() == ' ' || p.peek() == '\t') {
+			p.skip(" \t")
+			name = p.name(false)
+		}
+		if name == "" {
+			return nil, p.errorf("expected a variable name")
+		}
+		p.skip(" \t")
+		if p.done() || p.peek() != '=' {
+			return nil, p.errorf("expected = after %s", name)
+		}
+		p.pos++
+		p.skip(" \t")
+		value, err := p.value(lookup)
+		if err != nil {
+			return nil, err
+		}
+		entries[name] = value
+	}
+}
+
+type dotenvParser struct {
+	input string
+	pos   int
+	line  int
+}
+
+func (self *dotenvParser) done() bool {
+	return self.pos >= len(self.input)
+}
+
+func (self *dotenvParser) peek() byte {
+	return self.input[self.pos]
+}
+
+func (self *dotenvParser) next() byte {
+	c := self.input[self.pos]
+	self.pos++
+	if c == '\n' {
+		self.line++
+	}
+	return c
+}
+
+func (self *dotenvParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("dotenv: line %d: %s", self.line, fmt.Sprintf(format, args...))
+}
+
+// skips the characters in chars
+func (self *dotenvParser) skip(chars string) {
+	for !self.done() && strings.IndexByte(chars, self.peek()) >= 0 {
+		self.next()
+	}
+}
+
+// skips to the start of the next line
+func (self *dotenvParser) skipLine() {
+	for !self.done() && self.next() != '\n' {
+	}
+}
+
+// reads a variable name, names may contain . and - unless they are references in values
+func (self *dotenvParser) name(reference bool) string {
+	start := self.pos
+	for !self.done() && isDotenvName(self.peek(), self.pos > start, reference) {
+		self.pos++
+	}
+	return self.input[start:self.pos]
+}
+
+// reads a quoted or unquoted value and the rest of its line
+func (self *dotenvParser) value(lookup func(string) (string, bool)) (string, error) {
+	if self.done() {
+		return "", nil
+	}
+	var value string
+	switch quote := self.peek(); quote {
+	case '\'', '"':
+		self.next()
+		start, line := self.pos, self.line
+		var buffer bytes.Buffer
+		for {
+			if self.done() {
+				self.line = line
+				return "", self.errorf("unterminated %c quoted value", quote)
+			}
+			c := self.next()
+			if c == quote {
+				break
+			}
+			if c == '\\' && quote == '"' && !self.done() {
+				buffer.WriteString(unescapeDotenv(self.next()))
+				continue
+			}
+			if c == '$' && quote == '"' {
+				self.pos--
+				buffer.WriteString(self.expand(lookup))
+				continue
+			}
+			buffer.WriteByte(c)
+		}
+		if quote == '\'' {
+			value = self.input[start : self.pos-1]
+		} else {
+			value = buffer.String()
+		}
+		// only a comment may follow the closing quote
+		self.skip(" \t")
+		if !self.done() && self.peek() != '\n' && self.peek() != '#' {
+			return "", self.errorf("unexpected %q after quoted value", self.peek())
+		}
+		self.skipLine()
+	default:
+		end := strings.IndexByte(self.input[self.pos:], '\n')
+		if end < 0 {
+			end = len(self.input) - self.pos
+		}
+		raw := self.input[self.pos : self.pos+end]
+		// a # preceded by whitespace starts a comment
+		for i := 1; i < len(raw); i++ {
+			if raw[i] == '#' && (raw[i-1] == ' ' || raw[i-1] == '\t') {
+				raw = raw[:i]
+				break
+			}
+		}
+		expander := &dotenvParser{input: strings.TrimSpace(raw), line: self.line}
+		var buffer bytes.Buffer
+		for !expander.done() {
+			if expander.peek() == '$' {
+				buffer.WriteString(expander.expand(lookup))
+				continue
+			}
+			buffer.WriteByte(expander.next())
+		}
+		value = buffer.String()
+		self.skipLine()
+	}
+	return value, nil
+}
+
+// expands the $NAME, ${NAME} or ${NAME:-default} reference at the current position,
+// a $ that does not start a reference is kept as is.
+func (self *dotenvParser) expand(lookup func(string) (string, bool)) string {
+	self.next() // $
+	if self.done() {
+		return "$"
+	}
+	if self.peek() != '{' {
+		name := self.name(true)
+		if name == "" {
+			return "$"
+		}
+		value, _ := lookup(name)
+		return value
+	}
+	end := strings.IndexByte(self.input[self.pos:], '}')
+	if end < 0 {
+		return "$"
+	}
+	reference := self.input[self.pos+1 : self.pos+end]
+	self.pos += end + 1
+	name, fallback, hasFallback := strings.Cut(reference, ":-")
+	value, ok := lookup(name)
+	if hasFallback && (!ok || value == "") {
+		return fallback
+	}
+	return value
+}
+
+// reports whether name is read back as a whole variable name
+func validDotenvName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i := 0; i < len(name); i++ {
+		if !isDotenvName(name[i], i > 0, false) {
+			return false
+		}
+	}
+	return true
+}
+
+func isDotenvName(c byte, inside, reference bool) bool {
+	switch {
+	case c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z':
+		return true
+	case c >= '0' && c <= '9':
+		return inside
+	case c == '.' || c == '-':
+		return inside && !reference
+	}
+	return false
+}
+
+func unescapeDotenv(c byte) string {
+	switch c {
+	case 'n':
+		return "\n"
+	case 'r':
+		return "\r"
+	case 't':
+		return "\t"
+	case '"', '\\', '$':
+		return string(c)
+	}
+	return "\\" + string(c)
+}
+
+// double quotes value so it loads back as is
+func quoteDotenv(value string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", `\r`, "\t", `\t`, "$", `\$`)
+	return `"` + replacer.Replace(value) + `"`
+}
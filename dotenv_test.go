@@ -0,0 +1,94 @@
+package gonfig_test
+
+import (
+	. "github.com/Nomon/gonfig"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"io/ioutil"
+	"os"
+)
+
+var _ = Describe("DotenvConfig", func() {
+	var (
+		err error
+		cfg WritableConfig
+	)
+
+	BeforeEach(func() {
+		os.Setenv("GONFIG_DOTENV_TEST", "from process")
+		cfg, err = OpenDotenvConfig("./config_valid.env", "")
+	})
+	AfterEach(func() {
+		os.Unsetenv("GONFIG_DOTENV_TEST")
+	})
+
+	It("Should not error", func() {
+		Expect(err).NotTo(HaveOccurred())
+	})
+	It("Should parse export prefixes, whitespace and comments", func() {
+		Expect(cfg.Get("APP_HOST")).To(Equal("localhost"))
+		Expect(cfg.Get("APP_PORT")).To(Equal("8080"))
+		Expect(cfg.All()).To(HaveLen(9))
+	})
+	It("Should expand earlier entries and the process environment", func() {
+		Expect(cfg.Get("APP_URL")).To(Equal("http://localhost:8080/path#fragment"))
+		Expect(cfg.Get("APP_FROM_ENV")).To(Equal("from process"))
+		Expect(cfg.Get("APP_DEFAULT")).To(Equal("fallback"))
+	})
+	It("Should handle quoting and escapes", func() {
+		Expect(cfg.Get("APP_SINGLE")).To(Equal(`literal ${APP_HOST} \n`))
+		Expect(cfg.Get("APP_DOUBLE")).To(Equal("escaped \"quote\" $APP_HOST\ttab"))
+		Expect(cfg.Get("APP_MULTI")).To(Equal("first line\nsecond line"))
+	})
+	It("Should strip the prefix like EnvConfig", func() {
+		cfg, err = OpenDotenvConfig("./config_valid.env", "APP_")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(cfg.Get("HOST")).To(Equal("localhost"))
+		Expect(cfg.Get("URL")).To(Equal("http://localhost:8080/path#fragment"))
+		_, ok := cfg.(*DotenvConfig).Lookup("OTHER")
+		Expect(ok).To(BeFalse())
+	})
+	It("Should error for invalid files", func() {
+		cfg, err = OpenDotenvConfig("./config_invalid.env", "")
+		Expect(err).To(MatchError(ContainSubstring("line 1")))
+		cfg, err = OpenDotenvConfig("./config_nonexisting.env", "")
+		Expect(os.IsNotExist(err)).To(BeTrue())
+	})
+
+	Describe("Save", func() {
+		path := "./config_test.env"
+		AfterEach(func() {
+			os.Remove(path)
+		})
+		It("Should save values that load back the same", func() {
+			saved := NewDotenvConfig(path, "")
+			saved.Reset(cfg.All())
+			Expect(saved.Save()).To(Succeed())
+			Expect(NewDotenvConfig(path, "").All()).To(Equal(cfg.All()))
+		})
+		It("Should map nested keys to the Separator", func() {
+			saved := NewMappedDotenvConfig(path, "APP_", "__")
+			saved.Set("db:host", "x")
+			Expect(saved.Save()).To(Succeed())
+			data, err := ioutil.ReadFile(path)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(data)).To(Equal("APP_db__host=\"x\"\n"))
+			Expect(NewMappedDotenvConfig(path, "APP_", "__").All()).To(Equal(map[string]string{"db:host": "x"}))
+		})
+		It("Should fail saving keys that do not load back", func() {
+			saved := NewDotenvConfig(path, "APP_")
+			saved.Set("db:host", "x")
+			Expect(saved.Save()).To(MatchError(ContainSubstring(`key "db:host"`)))
+			_, err := os.Stat(path)
+			Expect(os.IsNotExist(err)).To(BeTrue())
+		})
+		It("Should prepend the prefix", func() {
+			saved := NewDotenvConfig(path, "APP_")
+			saved.Set("HOST", "$HOME")
+			Expect(saved.Save()).To(Succeed())
+			data, err := ioutil.ReadFile(path)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(data)).To(Equal("APP_HOST=\"\\$HOME\"\n"))
+		})
+	})
+})
@@ -10,26 +10,82 @@ import (
 type EnvConfig struct {
 	Configurable
 	Prefix string
+	// Separator in variable names that is mapped to the ":" key separator,
+	// with a Separator of "__" MYAPP_DB__HOST is found from "DB:HOST"
+	Separator string
+	// Lowercase the loaded keys, so MYAPP_DB__HOST is found from "db:host"
+	Lowercase bool
+	// environment variable names of the loaded keys
+	names MemoryConfig
 }
 
 // Creates a new Env config backed by a memory config
 func NewEnvConfig(prefix string) ReadableConfig {
-	cfg := &EnvConfig{NewMemoryConfig(), prefix}
+	cfg := &EnvConfig{Configurable: NewMemoryConfig(), Prefix: prefix}
+	cfg.Load()
+	return cfg
+}
+
+// Creates a new Env config backed by a memory config that maps the variable names to keys,
+// separator is replaced by ":" in the names and the names are lowercased if lowercase is true.
+// NewMappedEnvConfig("MYAPP_", "__", true) loads MYAPP_DB__HOST to "db:host"
+// so it can override the nested "db": {"host": ...} of a json config.
+func NewMappedEnvConfig(prefix, separator string, lowercase bool) ReadableConfig {
+	cfg := &EnvConfig{Configurable: NewMemoryConfig(), Prefix: prefix, Separator: separator, Lowercase: lowercase}
 	cfg.Load()
 	return cfg
 }
 
 // Loads the data from os.Environ() to the underlaying Configurable.
-// if a Prefix is set then variables are imported with self.Prefix removed from the name
+// if a Prefix is set then only variables starting with Prefix are imported, with self.Prefix removed from the name
 // so MYAPP_test=1 exported in env and read from ENV by EnvConfig{Prefix:"MYAPP_"} can be found from
 // EnvConfig.Get("test")
 func (self *EnvConfig) Load() (err error) {
-	env := os.Environ()
-	for _, pair := range env {
-		kv := strings.Split(pair, "=")
-		if kv != nil && len(kv) >= 2 {
-			self.Set(strings.Replace(kv[0], self.Prefix, "", 1), kv[1])
+	values := make(map[string]string)
+	names := make(map[string]string)
+	for _, pair := range os.Environ() {
+		name, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			continue
 		}
+		key, ok := envKey(self.Prefix, name)
+		if !ok || key == "" {
+			continue
+		}
+		if self.Separator != "" {
+			key = strings.Replace(key, self.Separator, ":", -1)
+		}
+		if self.Lowercase {
+			key = strings.ToLower(key)
+		}
+		values[key] = value
+		names[key] = name
 	}
+	self.names.Reset(names)
+	self.Configurable.Reset(values)
 	return nil
 }
+
+// Lookup key from the underlaying Configurable
+func (self *EnvConfig) Lookup(key string) (string, bool) {
+	return LookupConfig(self.Configurable, key)
+}
+
+// Observe changes to the underlaying Configurable
+func (self *EnvConfig) Observe(fn func()) func() {
+	return ObserveConfig(self.Configurable, fn)
+}
+
+// Returns the name of the environment variable key was loaded from
+func (self *EnvConfig) Origin(key string) string {
+	return self.names.Get(key)
+}
+
+// returns the config key of the environment variable name with prefix removed from its start,
+// ok is false if name does not start with prefix
+func envKey(prefix, name string) (key string, ok bool) {
+	if !strings.HasPrefix(name, prefix) {
+		return "", false
+	}
+	return strings.TrimPrefix(name, prefix), true
+}
@@ -14,17 +14,51 @@ var _ = Describe("EnvConfig", func() {
 		cfg ReadableConfig
 	)
 	BeforeEach(func() {
+		os.Setenv("GONFIG_TEST_NAME", "value")
+		os.Setenv("GONFIG_TEST_QUERY", "a=b=c")
+		os.Setenv("GONFIG_TEST_DB__HOST", "localhost")
+		os.Setenv("OTHER_GONFIG_TEST_NAME", "other")
 		cfg = NewEnvConfig("")
 		err = cfg.Load()
 	})
+	AfterEach(func() {
+		os.Unsetenv("GONFIG_TEST_NAME")
+		os.Unsetenv("GONFIG_TEST_QUERY")
+		os.Unsetenv("GONFIG_TEST_DB__HOST")
+		os.Unsetenv("OTHER_GONFIG_TEST_NAME")
+	})
 	It("Should load variables from environment", func() {
+		Expect(err).ToNot(HaveOccurred())
 		Expect(len(cfg.All()) > 0).To(BeTrue())
 		env := os.Environ()
 		Expect(len(env) > 0).To(BeTrue())
 		for _, kvpair := range env {
-			pairs := strings.Split(kvpair, "=")
-			Expect(len(pairs) >= 2).To(BeTrue())
+			pairs := strings.SplitN(kvpair, "=", 2)
+			Expect(len(pairs)).To(Equal(2))
 			Expect(cfg.Get(pairs[0])).To(Equal(pairs[1]))
 		}
 	})
+	It("Should keep values containing =", func() {
+		Expect(cfg.Get("GONFIG_TEST_QUERY")).To(Equal("a=b=c"))
+	})
+	It("Should import only variables starting with the prefix", func() {
+		cfg = NewEnvConfig("GONFIG_TEST_")
+		Expect(cfg.All()).To(Equal(map[string]string{"NAME": "value", "QUERY": "a=b=c", "DB__HOST": "localhost"}))
+		Expect(cfg.(*EnvConfig).Origin("NAME")).To(Equal("GONFIG_TEST_NAME"))
+	})
+	It("Should map the variable names to nested keys", func() {
+		cfg = NewMappedEnvConfig("GONFIG_TEST_", "__", true)
+		Expect(cfg.Get("db:host")).To(Equal("localhost"))
+		Expect(cfg.Get("name")).To(Equal("value"))
+		Expect(cfg.(*EnvConfig).Origin("db:host")).To(Equal("GONFIG_TEST_DB__HOST"))
+	})
+	It("Should override nested json keys", func() {
+		os.Setenv("GONFIG_TEST_TEST_OBJECT__NESTED_STRING", "from env")
+		defer os.Unsetenv("GONFIG_TEST_TEST_OBJECT__NESTED_STRING")
+		conf := NewConfig(nil)
+		conf.Use("env", NewMappedEnvConfig("GONFIG_TEST_", "__", true))
+		conf.Use("json", NewJsonConfig("./config_valid.json"))
+		Expect(conf.Get("test_object:nested_string")).To(Equal("from env"))
+		Expect(conf.Get("test_object:nested_int")).To(Equal("987"))
+	})
 })
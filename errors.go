@@ -0,0 +1,166 @@
+package gonfig
+
+import (
+	"errors"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// The error of loading a single mount in the hierarchy
+type MountError struct {
+	// Names of the layers from the root Gonfig down to the failing config
+	Path []string
+	Err  error
+}
+
+func (self *MountError) Error() string {
+	return "mount " + strings.Join(self.Path, "/") + ": " + self.Err.Error()
+}
+
+// Returns the underlaying load error
+func (self *MountError) Unwrap() error {
+	return self.Err
+}
+
+// LoadErrors is returned by Gonfig.Load when one or more mounts failed to load
+type LoadErrors []*MountError
+
+func (self LoadErrors) Error() string {
+	messages := make([]string, len(self))
+	for i, err := range self {
+		messages[i] = err.Error()
+	}
+	return "gonfig: load failed: " + strings.Join(messages, "; ")
+}
+
+// Returns the errors of the failing mounts
+func (self LoadErrors) Unwrap() []error {
+	errs := make([]error, len(self))
+	for i, err := range self {
+		errs[i] = err
+	}
+	return errs
+}
+
+// adds the load error of the config mounted as name, errors of nested hierarchies are
+// added with their path prefixed by name. Missing sources are skipped for optional mounts.
+func (self LoadErrors) add(name string, err error, optional bool) LoadErrors {
+	if err == nil {
+		return self
+	}
+	if nested, ok := err.(LoadErrors); ok {
+		for _, mountErr := range nested {
+			if optional && isNotExist(mountErr.Err) {
+				continue
+			}
+			self = append(self, &MountError{append([]string{name}, mountErr.Path...), mountErr.Err})
+		}
+		return self
+	}
+	if optional && isNotExist(err) {
+		return self
+	}
+	return append(self, &MountError{[]string{name}, err})
+}
+
+// returns self as an error or nil if there are no errors
+func (self LoadErrors) err() error {
+	if len(self) == 0 {
+		return nil
+	}
+	return self
+}
+
+// reports whether err means that the source of a config does not exist
+func isNotExist(err error) bool {
+	return errors.Is(err, os.ErrNotExist)
+}
+
+// ErrNotSet is returned by the typed getters when the key is not set or is empty
+var ErrNotSet = errors.New("not set")
+
+// The error of converting the value of a key to a type
+type KeyError struct {
+	Key string
+	// Path of the struct field the key was decoded into by Marshal, ie. "Database.Port"
+	Field string
+	Value string
+	Err   error
+}
+
+func (self *KeyError) Error() string {
+	message := "key " + strconv.Quote(self.Key)
+	if self.Field != "" {
+		message += " (field " + self.Field + ")"
+	}
+	if self.Value == "" {
+		return message + ": " + self.Err.Error()
+	}
+	return message + ": invalid value " + strconv.Quote(self.Value) + ": " + self.Err.Error()
+}
+
+// Returns the underlaying conversion error or ErrNotSet
+func (self *KeyError) Unwrap() error {
+	return self.Err
+}
+
+// ErrRequired is the error of a field tagged required:"true" whose key is not set in any layer
+var ErrRequired = errors.New("required key is not set")
+
+// MarshalErrors is returned by Marshal when one or more fields failed to decode or validate
+type MarshalErrors []*KeyError
+
+func (self MarshalErrors) Error() string {
+	messages := make([]string, len(self))
+	for i, err := range self {
+		messages[i] = err.Error()
+	}
+	return "gonfig: marshal failed: " + strings.Join(messages, "; ")
+}
+
+// Returns the errors of the failing fields
+func (self MarshalErrors) Unwrap() []error {
+	errs := make([]error, len(self))
+	for i, err := range self {
+		errs[i] = err
+	}
+	return errs
+}
+
+// Returns the keys of the required fields that are not set
+func (self MarshalErrors) Missing() []string {
+	missing := []string{}
+	for _, err := range self {
+		if err.Err == ErrRequired {
+			missing = append(missing, err.Key)
+		}
+	}
+	return missing
+}
+
+// returns self as an error or nil if there are no errors
+func (self MarshalErrors) err() error {
+	if len(self) == 0 {
+		return nil
+	}
+	return self
+}
+
+// ErrCycle is the error of interpolating a value that references itself
+var ErrCycle = errors.New("interpolation cycle")
+
+// The error of fetching a document that responded with a status code other than 2xx
+type StatusError struct {
+	Url        string
+	StatusCode int
+	// The status line of the response, ie. "404 Not Found"
+	Status string
+}
+
+func (self *StatusError) Error() string {
+	return "gonfig: GET " + self.Url + ": " + self.Status
+}
+
+// ErrUnknownFormat is the error of loading a document in a format that is not registered
+var ErrUnknownFormat = errors.New("unknown format")
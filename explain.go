@@ -0,0 +1,81 @@
+package gonfig
+
+// A Configurable that can tell where its value for a key originates from,
+// ie. the file path, url, environment variable or command-line flag.
+type OriginConfigurable interface {
+	Configurable
+	// Returns the origin of key or "" if it is unknown
+	Origin(string) string
+}
+
+// Names of the layers of a Gonfig that are not mounts
+const (
+	OverrideSource = "override"
+	DefaultsSource = "defaults"
+)
+
+// A layer of the hierarchy that has a value for a key
+type Source struct {
+	// Name of the layer in the Gonfig Explain was called on,
+	// OverrideSource, the name of a mount or DefaultsSource
+	Name string
+	// Names of the layers from the root Gonfig down to the config the value was found in,
+	// nested Gonfig hierarchies add their own layer names to the path
+	Path []string
+	// Origin of the value within the config, empty if the config is not an OriginConfigurable
+	Origin string
+	// The value set in this layer
+	Value string
+}
+
+// Explains how a key is resolved by Gonfig.Get
+type Explanation struct {
+	Key string
+	// Whether any layer of the hierarchy has the key set
+	Found bool
+	// The source the value is resolved from, zero value if the key was not found
+	Source
+	// Values set for the key in the layers searched after Source, in search order
+	Shadowed []Source
+}
+
+// Explains where the value for key is resolved from, which mount provided it and what
+// values from layers later in the search order it shadows.
+// conf.Explain("database").Name == "local".
+// conf.Explain("database").Origin == "./config.json".
+func (self *Gonfig) Explain(key string) *Explanation {
+	explanation := &Explanation{Key: key}
+	sources := self.sources(key, nil)
+	if len(sources) > 0 {
+		explanation.Found = true
+		explanation.Source = sources[0]
+		explanation.Shadowed = sources[1:]
+	}
+	return explanation
+}
+
+// returns all layers that have key set in search order, path is the path to self
+func (self *Gonfig) sources(key string, path []string) []Source {
+	sources := configSources(OverrideSource, self.Configurable, key, path)
+	for _, mount := range self.snapshot() {
+		sources = append(sources, configSources(mount.Name, mount.Configurable, key, path)...)
+	}
+	return append(sources, configSources(DefaultsSource, self.Defaults, key, path)...)
+}
+
+// returns the sources of key in config mounted as name, descends into nested Gonfigs
+func configSources(name string, config Configurable, key string, path []string) []Source {
+	path = append(append([]string{}, path...), name)
+	if nested, ok := config.(*Gonfig); ok {
+		return nested.sources(key, path)
+	}
+	value, ok := LookupConfig(config, key)
+	if !ok {
+		return nil
+	}
+	source := Source{Name: path[0], Path: path, Value: value}
+	if origin, ok := config.(OriginConfigurable); ok {
+		source.Origin = origin.Origin(key)
+	}
+	return []Source{source}
+}
@@ -0,0 +1,52 @@
+package gonfig_test
+
+import (
+	. "github.com/Nomon/gonfig"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"os"
+)
+
+var _ = Describe("Explain", func() {
+	var cfg *Gonfig
+	BeforeEach(func() {
+		os.Setenv("GONFIG_EXPLAIN_test", "from_env")
+		cfg = NewConfig(nil)
+		cfg.Use("env", NewEnvConfig("GONFIG_EXPLAIN_"))
+		cfg.Use("local", NewJsonConfig("./config_valid.json"))
+		nested := NewConfig(nil)
+		nested.Defaults.Set("test", "nested_default")
+		cfg.Use("nested", nested)
+		cfg.Defaults.Set("test", "default")
+	})
+	AfterEach(func() {
+		os.Unsetenv("GONFIG_EXPLAIN_test")
+	})
+	It("Should explain the winning source and its origin", func() {
+		explanation := cfg.Explain("test")
+		Expect(explanation.Found).To(BeTrue())
+		Expect(explanation.Name).To(Equal("env"))
+		Expect(explanation.Path).To(Equal([]string{"env"}))
+		Expect(explanation.Origin).To(Equal("GONFIG_EXPLAIN_test"))
+		Expect(explanation.Value).To(Equal("from_env"))
+	})
+	It("Should list the shadowed values in search order", func() {
+		shadowed := cfg.Explain("test").Shadowed
+		Expect(shadowed).To(HaveLen(3))
+		Expect(shadowed[0]).To(Equal(Source{"local", []string{"local"}, "./config_valid.json", "123"}))
+		Expect(shadowed[1]).To(Equal(Source{"nested", []string{"nested", DefaultsSource}, "", "nested_default"}))
+		Expect(shadowed[2]).To(Equal(Source{DefaultsSource, []string{DefaultsSource}, "", "default"}))
+	})
+	It("Should explain overrides", func() {
+		cfg.Set("test", "override")
+		explanation := cfg.Explain("test")
+		Expect(explanation.Name).To(Equal(OverrideSource))
+		Expect(explanation.Value).To(Equal(cfg.Get("test")))
+		Expect(explanation.Shadowed).To(HaveLen(4))
+	})
+	It("Should not find unset keys", func() {
+		explanation := cfg.Explain("nonexisting")
+		Expect(explanation.Found).To(BeFalse())
+		Expect(explanation.Shadowed).To(BeEmpty())
+	})
+})
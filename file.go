@@ -0,0 +1,179 @@
+package gonfig
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+// FileConfig is embedded by the configs backed by a file, it holds the loaded values and the path
+// of the file and implements the methods the file configs share.
+// JsonConfig keeps its own Configurable and Path fields so existing JsonConfig literals keep working.
+type FileConfig struct {
+	Configurable
+	Path string
+}
+
+// returns a FileConfig for the file at path backed by backingConfig(cfg)
+func openFile(path string, cfg []Configurable) FileConfig {
+	return FileConfig{Configurable: backingConfig(cfg), Path: path}
+}
+
+// returns the first of cfg loaded, or a new MemoryConfig if cfg is empty
+func backingConfig(cfg []Configurable) Configurable {
+	if len(cfg) == 0 {
+		return NewMemoryConfig()
+	}
+	LoadConfig(cfg[0])
+	return cfg[0]
+}
+
+// Lookup key from the underlaying Configurable
+func (self *FileConfig) Lookup(key string) (string, bool) {
+	return LookupConfig(self.Configurable, key)
+}
+
+// Observe changes to the underlaying Configurable
+func (self *FileConfig) Observe(fn func()) func() {
+	return ObserveConfig(self.Configurable, fn)
+}
+
+// Returns the Path of the file for keys set in the config
+func (self *FileConfig) Origin(key string) string {
+	if _, ok := self.Lookup(key); ok {
+		return self.Path
+	}
+	return ""
+}
+
+// starts watching the file at path and reloads config whenever it changes, the file is checked
+// for changes every interval. Stop on the returned Watcher stops watching.
+func watchFile(config ReadableConfig, path string, interval time.Duration) *Watcher {
+	watcher := NewWatcher(config, path, interval)
+	watcher.Start()
+	return watcher
+}
+
+// writes data to the file at path atomically, data is written to a temporary file in the same directory
+// that is synced to disk and renamed over path, so a crash never leaves a partially written file at path.
+// The mode and owner of an existing file are kept, new files are created with mode 0600.
+// If backups is more than 0 the existing file is kept as path.1 and older backups are rotated up to path.backups.
+func writeFile(path string, data []byte, backups int) error {
+	// write through symlinks instead of replacing them
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		path = resolved
+	}
+	mode := os.FileMode(0600)
+	existing, err := os.Stat(path)
+	if err == nil {
+		mode = existing.Mode().Perm()
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+
+	dir := filepath.Dir(path)
+	temp, err := ioutil.TempFile(dir, "."+filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+	// removes the temporary file if it was not renamed to path
+	defer os.Remove(temp.Name())
+	if _, err := temp.Write(data); err != nil {
+		temp.Close()
+		return err
+	}
+	if err := temp.Sync(); err != nil {
+		temp.Close()
+		return err
+	}
+	if err := temp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(temp.Name(), mode); err != nil {
+		return err
+	}
+	if existing != nil {
+		if err := chown(temp.Name(), existing); err != nil {
+			return err
+		}
+		if backups > 0 {
+			if err := rotateBackups(path, backups); err != nil {
+				return err
+			}
+		}
+	}
+	if err := os.Rename(temp.Name(), path); err != nil {
+		return err
+	}
+	syncDir(dir)
+	return nil
+}
+
+// returns the path of the nth backup of the file at path
+func backupPath(path string, n int) string {
+	return path + "." + strconv.Itoa(n)
+}
+
+// shifts the backups of the file at path up by one, dropping the ones over backups,
+// and keeps the current file as the first backup
+func rotateBackups(path string, backups int) error {
+	if err := os.Remove(backupPath(path, backups)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	for n := backups - 1; n > 0; n-- {
+		if err := os.Rename(backupPath(path, n), backupPath(path, n+1)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	// the current file stays in place until it is replaced, link it as the backup or copy it if linking fails
+	if err := os.Link(path, backupPath(path, 1)); err == nil {
+		return nil
+	}
+	return copyFile(path, backupPath(path, 1))
+}
+
+// restores the nth backup of the file at path by atomically writing it over path, the backups are kept
+func restoreBackup(path string, n int) error {
+	if n < 1 {
+		return errors.New("gonfig: backup number must be at least 1, got " + strconv.Itoa(n))
+	}
+	data, err := ioutil.ReadFile(backupPath(path, n))
+	if err != nil {
+		return err
+	}
+	return writeFile(path, data, 0)
+}
+
+func copyFile(from, to string) error {
+	source, err := os.Open(from)
+	if err != nil {
+		return err
+	}
+	defer source.Close()
+	info, err := source.Stat()
+	if err != nil {
+		return err
+	}
+	target, err := os.OpenFile(to, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(target, source); err != nil {
+		target.Close()
+		return err
+	}
+	return target.Close()
+}
+
+// syncs the directory so a rename in it is durable, directories can not be opened
+// or synced on all platforms so this is done on a best effort basis
+func syncDir(dir string) {
+	if d, err := os.Open(dir); err == nil {
+		d.Sync()
+		d.Close()
+	}
+}
@@ -0,0 +1,10 @@
+//go:build !unix
+
+package gonfig
+
+import "os"
+
+// files do not have an owner to keep on this platform
+func chown(path string, existing os.FileInfo) error {
+	return nil
+}
@@ -0,0 +1,22 @@
+//go:build unix
+
+package gonfig
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+// gives the file at path the owner and group of existing. Changing the owner requires privileges,
+// without them the file is left owned by the current user instead of failing the save.
+func chown(path string, existing os.FileInfo) error {
+	stat, ok := existing.Sys().(*syscall.Stat_t)
+	if !ok || int(stat.Uid) == os.Getuid() && int(stat.Gid) == os.Getgid() {
+		return nil
+	}
+	if err := os.Chown(path, int(stat.Uid), int(stat.Gid)); err != nil && !errors.Is(err, os.ErrPermission) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,151 @@
+package gonfig
+
+import (
+	"fmt"
+	"mime"
+	"net/url"
+	"path"
+	"strings"
+	"sync"
+)
+
+// Decoder parses a document into flattened ":" separated keys and their values
+type Decoder func(data []byte) (map[string]string, error)
+
+// Format is a document format UrlConfig can decode, formats are selected by Name,
+// the media type of the Content-Type header or the extension of the url path.
+type Format struct {
+	Name   string
+	Decode Decoder
+	// Media types of the format, ie. "application/yaml", the first one is sent in the Accept header
+	// of UrlConfigs that have the Format set
+	MediaTypes []string
+	// Url path extensions of the format including the dot, ie. ".yaml"
+	Extensions []string
+}
+
+// the registered formats by name, media type and extension
+var formats = struct {
+	sync.RWMutex
+	names      map[string]*Format
+	mediaTypes map[string]*Format
+	extensions map[string]*Format
+}{
+	names:      make(map[string]*Format),
+	mediaTypes: make(map[string]*Format),
+	extensions: make(map[string]*Format),
+}
+
+func init() {
+	RegisterFormat(Format{
+		Name: "json",
+		Decode: func(data []byte) (map[string]string, error) {
+			out, _, err := unmarshalJson(data)
+			return out, err
+		},
+		MediaTypes: []string{"application/json", "text/json"},
+		Extensions: []string{".json"},
+	})
+	RegisterFormat(Format{
+		Name: "yaml",
+		Decode: func(data []byte) (map[string]string, error) {
+			out, _, err := unmarshalYaml(data)
+			return out, err
+		},
+		MediaTypes: []string{"application/yaml", "application/x-yaml", "text/yaml", "text/x-yaml"},
+		Extensions: []string{".yaml", ".yml"},
+	})
+	RegisterFormat(Format{
+		Name: "toml",
+		Decode: func(data []byte) (map[string]string, error) {
+			out, _, err := unmarshalToml(data)
+			return out, err
+		},
+		MediaTypes: []string{"application/toml", "text/toml", "application/x-toml"},
+		Extensions: []string{".toml"},
+	})
+	RegisterFormat(Format{
+		Name:       "properties",
+		Decode:     unmarshalProperties,
+		MediaTypes: []string{"text/x-java-properties", "text/x-properties"},
+		Extensions: []string{".properties"},
+	})
+	RegisterFormat(Format{
+		Name:       "ini",
+		Decode:     unmarshalIni,
+		MediaTypes: []string{"text/x-ini"},
+		Extensions: []string{".ini"},
+	})
+	// fetched documents must not copy values of the local environment into the config,
+	// so their references are expanded only from the document
+	RegisterFormat(Format{
+		Name: "dotenv",
+		Decode: func(data []byte) (map[string]string, error) {
+			return unmarshalDotenv(data, false)
+		},
+		Extensions: []string{".env"},
+	})
+}
+
+// Registers format for UrlConfig, a format registered with the name, a media type or an extension
+// of an already registered format replaces it for those. Formats are registered before
+// loading configs, ie. in an init function.
+func RegisterFormat(format Format) {
+	if format.Name == "" || format.Decode == nil {
+		panic("gonfig: RegisterFormat requires a Name and a Decode function")
+	}
+	formats.Lock()
+	defer formats.Unlock()
+	registered := &format
+	formats.names[format.Name] = registered
+	for _, mediaType := range format.MediaTypes {
+		formats.mediaTypes[strings.ToLower(mediaType)] = registered
+	}
+	for _, extension := range format.Extensions {
+		formats.extensions[strings.ToLower(extension)] = registered
+	}
+}
+
+// Returns the registered format called name
+func LookupFormat(name string) (Format, bool) {
+	formats.RLock()
+	defer formats.RUnlock()
+	if format, ok := formats.names[name]; ok {
+		return *format, true
+	}
+	return Format{}, false
+}
+
+// returns the format of a document with contentType fetched from rawUrl, the media type of contentType
+// is preferred over the extension of the url path and documents of unknown formats are decoded as json.
+// Structured syntax suffixes are matched too, so "application/vnd.app+yaml" is decoded as yaml.
+func detectFormat(contentType, rawUrl string) *Format {
+	formats.RLock()
+	defer formats.RUnlock()
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil {
+		if format, ok := formats.mediaTypes[mediaType]; ok {
+			return format
+		}
+		if i := strings.LastIndex(mediaType, "+"); i != -1 {
+			if format, ok := formats.names[mediaType[i+1:]]; ok {
+				return format
+			}
+		}
+	}
+	if parsed, err := url.Parse(rawUrl); err == nil {
+		if format, ok := formats.extensions[strings.ToLower(path.Ext(parsed.Path))]; ok {
+			return format
+		}
+	}
+	return formats.names["json"]
+}
+
+// returns the registered format called name
+func namedFormat(name string) (*Format, error) {
+	formats.RLock()
+	defer formats.RUnlock()
+	if format, ok := formats.names[name]; ok {
+		return format, nil
+	}
+	return nil, fmt.Errorf("gonfig: %w %q", ErrUnknownFormat, name)
+}
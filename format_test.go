@@ -0,0 +1,127 @@
+package gonfig_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+
+	. "github.com/Nomon/gonfig"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Format", func() {
+	var (
+		server      *httptest.Server
+		contentType string
+		accept      string
+		documents   = map[string]string{
+			"/config.json":       `{"db":{"host":"json"}}`,
+			"/config.yaml":       "db:\n  host: yaml\n",
+			"/config.yml":        "db:\n  host: yml\n",
+			"/config.toml":       "[db]\nhost = \"toml\"\n",
+			"/config.properties": "db\\:host=properties\n",
+			"/config":            "db:\n  host: negotiated\n",
+			"/config.txt":        "db:\n  host: forced\n",
+			"/config.kv":         "db:host|custom\n",
+			"/config.env":        "HOST=remote\nURL=http://${HOST}/?k=${GONFIG_TEST_SECRET}\n",
+		}
+	)
+	BeforeEach(func() {
+		contentType = ""
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			accept = r.Header.Get("Accept")
+			if contentType != "" {
+				w.Header().Set("Content-Type", contentType)
+			}
+			w.Write([]byte(documents[r.URL.Path]))
+		}))
+	})
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("Should choose the format by the url extension", func() {
+		for path, host := range map[string]string{
+			"/config.json":       "json",
+			"/config.yaml":       "yaml",
+			"/config.yml":        "yml",
+			"/config.toml":       "toml",
+			"/config.properties": "properties",
+		} {
+			url := NewUrlConfig(server.URL + path + "?version=2")
+			Expect(url.Load()).To(Succeed(), path)
+			Expect(url.Get("db:host")).To(Equal(host), path)
+		}
+	})
+
+	It("Should not expand dotenv references from the local environment", func() {
+		os.Setenv("GONFIG_TEST_SECRET", "secret")
+		defer os.Unsetenv("GONFIG_TEST_SECRET")
+		url := NewUrlConfig(server.URL + "/config.env")
+		Expect(url.Load()).To(Succeed())
+		Expect(url.Get("URL")).To(Equal("http://remote/?k="))
+	})
+
+	It("Should prefer the Content-Type over the url extension", func() {
+		contentType = "application/yaml; charset=utf-8"
+		url := NewUrlConfig(server.URL + "/config")
+		Expect(url.Load()).To(Succeed())
+		Expect(url.Get("db:host")).To(Equal("negotiated"))
+		contentType = "application/vnd.myapp+yaml"
+		Expect(url.Load()).To(Succeed())
+		Expect(url.Get("db:host")).To(Equal("negotiated"))
+	})
+
+	It("Should fall back to the extension and json for unknown content types", func() {
+		contentType = "text/plain"
+		url := NewUrlConfig(server.URL + "/config.yaml")
+		Expect(url.Load()).To(Succeed())
+		Expect(url.Get("db:host")).To(Equal("yaml"))
+		url = NewUrlConfig(server.URL + "/config.json")
+		Expect(url.Load()).To(Succeed())
+		Expect(url.Get("db:host")).To(Equal("json"))
+	})
+
+	It("Should decode with an explicit format and send its media type in Accept", func() {
+		contentType = "text/plain"
+		url := &UrlConfig{Configurable: NewMemoryConfig(), Url: server.URL + "/config.txt", Format: "yaml"}
+		Expect(url.Load()).To(Succeed())
+		Expect(url.Get("db:host")).To(Equal("forced"))
+		Expect(accept).To(Equal("application/yaml"))
+	})
+
+	It("Should fail loading an unknown format", func() {
+		url := &UrlConfig{Configurable: NewMemoryConfig(), Url: server.URL + "/config.json", Format: "xml"}
+		err := url.Load()
+		Expect(errors.Is(err, ErrUnknownFormat)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring(`"xml"`))
+	})
+
+	It("Should decode registered formats", func() {
+		RegisterFormat(Format{
+			Name: "kv",
+			Decode: func(data []byte) (map[string]string, error) {
+				out := make(map[string]string)
+				for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+					if key, value, ok := strings.Cut(line, "|"); ok {
+						out[key] = value
+					}
+				}
+				return out, nil
+			},
+			MediaTypes: []string{"text/x-kv"},
+			Extensions: []string{".kv"},
+		})
+		format, ok := LookupFormat("kv")
+		Expect(ok).To(BeTrue())
+		Expect(format.Extensions).To(Equal([]string{".kv"}))
+		url := NewUrlConfig(server.URL + "/config.kv")
+		Expect(url.Load()).To(Succeed())
+		Expect(url.Get("db:host")).To(Equal("custom"))
+		_, ok = LookupFormat("missing")
+		Expect(ok).To(BeFalse())
+	})
+})
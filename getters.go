@@ -0,0 +1,218 @@
+package gonfig
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Typed getters convert the values of keys with the same rules Marshal uses: values are trimmed,
+// an empty value is not set, "0" and "false" are false and every other bool is true, lists are
+// comma separated and maps are comma separated name=value pairs.
+// The error returning getters return a *KeyError wrapping ErrNotSet for keys that are not set,
+// the Or variants return the default for keys that are not set or can not be converted.
+
+// Returns the value of key in config as an int
+func GetInt(config Configurable, key string) (int, error) {
+	return getTyped(config, key, func(value string) (int, error) {
+		i, err := strconv.ParseInt(value, 10, 0)
+		return int(i), unwrapNumError(err)
+	})
+}
+
+// Returns the value of key in config as an int or def
+func GetIntOr(config Configurable, key string, def int) int {
+	if value, err := GetInt(config, key); err == nil {
+		return value
+	}
+	return def
+}
+
+// Returns the value of key in config as a float64
+func GetFloat(config Configurable, key string) (float64, error) {
+	return getTyped(config, key, func(value string) (float64, error) {
+		f, err := strconv.ParseFloat(value, 64)
+		return f, unwrapNumError(err)
+	})
+}
+
+// Returns the value of key in config as a float64 or def
+func GetFloatOr(config Configurable, key string, def float64) float64 {
+	if value, err := GetFloat(config, key); err == nil {
+		return value
+	}
+	return def
+}
+
+// Returns the value of key in config as a bool, "0" and "false" are false, other values are true
+func GetBool(config Configurable, key string) (bool, error) {
+	return getTyped(config, key, func(value string) (bool, error) {
+		return parseBool(value), nil
+	})
+}
+
+// Returns the value of key in config as a bool or def
+func GetBoolOr(config Configurable, key string, def bool) bool {
+	if value, err := GetBool(config, key); err == nil {
+		return value
+	}
+	return def
+}
+
+// Returns the value of key in config as a time.Duration, the value is parsed with time.ParseDuration
+func GetDuration(config Configurable, key string) (time.Duration, error) {
+	return getTyped(config, key, time.ParseDuration)
+}
+
+// Returns the value of key in config as a time.Duration or def
+func GetDurationOr(config Configurable, key string, def time.Duration) time.Duration {
+	if value, err := GetDuration(config, key); err == nil {
+		return value
+	}
+	return def
+}
+
+// Returns the comma separated value of key in config as a slice of trimmed strings
+func GetStringSlice(config Configurable, key string) ([]string, error) {
+	return getTyped(config, key, func(value string) ([]string, error) {
+		return trimsplit(value, ","), nil
+	})
+}
+
+// Returns the comma separated value of key in config as a slice or def
+func GetStringSliceOr(config Configurable, key string, def []string) []string {
+	if value, err := GetStringSlice(config, key); err == nil {
+		return value
+	}
+	return def
+}
+
+// Returns the value of key in config as a map. A value of comma separated name=value pairs is split
+// into the map, if key has no value the keys nested under it are returned with the "key:" prefix removed,
+// so with "db:host" and "db:port" set GetStringMap(config, "db") returns the host and port.
+func GetStringMap(config Configurable, key string) (map[string]string, error) {
+	values, err := getTyped(config, key, parseMap)
+	if !errors.Is(err, ErrNotSet) {
+		return values, err
+	}
+	values = make(map[string]string)
+	for name, value := range config.All() {
+		if strings.HasPrefix(name, key+":") {
+			values[strings.TrimPrefix(name, key+":")] = value
+		}
+	}
+	if len(values) == 0 {
+		return nil, err
+	}
+	return values, nil
+}
+
+// Returns the value of key in config as a map or def
+func GetStringMapOr(config Configurable, key string, def map[string]string) map[string]string {
+	if value, err := GetStringMap(config, key); err == nil {
+		return value
+	}
+	return def
+}
+
+// Returns the value of key as an int
+func (self *Gonfig) GetInt(key string) (int, error) {
+	return GetInt(self, key)
+}
+
+// Returns the value of key as an int or def
+func (self *Gonfig) GetIntOr(key string, def int) int {
+	return GetIntOr(self, key, def)
+}
+
+// Returns the value of key as a float64
+func (self *Gonfig) GetFloat(key string) (float64, error) {
+	return GetFloat(self, key)
+}
+
+// Returns the value of key as a float64 or def
+func (self *Gonfig) GetFloatOr(key string, def float64) float64 {
+	return GetFloatOr(self, key, def)
+}
+
+// Returns the value of key as a bool
+func (self *Gonfig) GetBool(key string) (bool, error) {
+	return GetBool(self, key)
+}
+
+// Returns the value of key as a bool or def
+func (self *Gonfig) GetBoolOr(key string, def bool) bool {
+	return GetBoolOr(self, key, def)
+}
+
+// Returns the value of key as a time.Duration
+func (self *Gonfig) GetDuration(key string) (time.Duration, error) {
+	return GetDuration(self, key)
+}
+
+// Returns the value of key as a time.Duration or def
+func (self *Gonfig) GetDurationOr(key string, def time.Duration) time.Duration {
+	return GetDurationOr(self, key, def)
+}
+
+// Returns the value of key as a slice of strings
+func (self *Gonfig) GetStringSlice(key string) ([]string, error) {
+	return GetStringSlice(self, key)
+}
+
+// Returns the value of key as a slice of strings or def
+func (self *Gonfig) GetStringSliceOr(key string, def []string) []string {
+	return GetStringSliceOr(self, key, def)
+}
+
+// Returns the value of key as a map
+func (self *Gonfig) GetStringMap(key string) (map[string]string, error) {
+	return GetStringMap(self, key)
+}
+
+// Returns the value of key as a map or def
+func (self *Gonfig) GetStringMapOr(key string, def map[string]string) map[string]string {
+	return GetStringMapOr(self, key, def)
+}
+
+// looks up the trimmed value of key and converts it with parse
+func getTyped[T any](config Configurable, key string, parse func(string) (T, error)) (T, error) {
+	var zero T
+	value, _ := LookupConfig(config, key)
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return zero, &KeyError{Key: key, Err: ErrNotSet}
+	}
+	converted, err := parse(value)
+	if err != nil {
+		return zero, &KeyError{Key: key, Value: value, Err: err}
+	}
+	return converted, nil
+}
+
+// the bool rule of Marshal, "0" and "false" are false and everything else is true
+func parseBool(value string) bool {
+	return value != "0" && value != "false"
+}
+
+// parses comma separated name=value pairs
+func parseMap(value string) (map[string]string, error) {
+	values := make(map[string]string)
+	for _, pair := range trimsplit(value, ",") {
+		name, v, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, errors.New("expected name=value, got " + strconv.Quote(pair))
+		}
+		values[strings.TrimSpace(name)] = strings.TrimSpace(v)
+	}
+	return values, nil
+}
+
+// strips the function and input from strconv errors, the KeyError already names the value
+func unwrapNumError(err error) error {
+	if numErr, ok := err.(*strconv.NumError); ok {
+		return numErr.Err
+	}
+	return err
+}
@@ -0,0 +1,79 @@
+package gonfig_test
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	. "github.com/Nomon/gonfig"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Typed getters", func() {
+	var cfg *Gonfig
+	BeforeEach(func() {
+		cfg = NewConfig(nil)
+		cfg.Use("memory", NewMemoryConfig()).Reset(map[string]string{
+			"int":      " 42 ",
+			"float":    "12.5",
+			"bool":     "false",
+			"yes":      "yes",
+			"duration": "1m30s",
+			"list":     "a, b ,c",
+			"map":      "a=1, b = 2",
+			"db:host":  "localhost",
+			"db:port":  "5432",
+			"invalid":  "abc",
+			"empty":    "",
+		})
+	})
+	It("Should convert values to their types", func() {
+		Expect(cfg.GetInt("int")).To(Equal(42))
+		Expect(cfg.GetFloat("float")).To(Equal(12.5))
+		Expect(cfg.GetBool("bool")).To(BeFalse())
+		Expect(cfg.GetBool("yes")).To(BeTrue())
+		Expect(cfg.GetDuration("duration")).To(Equal(90 * time.Second))
+		Expect(cfg.GetStringSlice("list")).To(Equal([]string{"a", "b", "c"}))
+		Expect(cfg.GetStringMap("map")).To(Equal(map[string]string{"a": "1", "b": "2"}))
+	})
+	It("Should return the nested keys as a map", func() {
+		Expect(cfg.GetStringMap("db")).To(Equal(map[string]string{"host": "localhost", "port": "5432"}))
+	})
+	It("Should return ErrNotSet for missing and empty keys", func() {
+		_, err := cfg.GetInt("missing")
+		Expect(errors.Is(err, ErrNotSet)).To(BeTrue())
+		_, err = cfg.GetBool("empty")
+		Expect(errors.Is(err, ErrNotSet)).To(BeTrue())
+		_, err = cfg.GetStringMap("missing")
+		Expect(errors.Is(err, ErrNotSet)).To(BeTrue())
+	})
+	It("Should return a KeyError for invalid values", func() {
+		_, err := cfg.GetInt("invalid")
+		Expect(err).To(MatchError(`key "invalid": invalid value "abc": invalid syntax`))
+		var keyErr *KeyError
+		Expect(errors.As(err, &keyErr)).To(BeTrue())
+		Expect(keyErr.Key).To(Equal("invalid"))
+		Expect(errors.Is(err, strconv.ErrSyntax)).To(BeTrue())
+		_, err = cfg.GetDuration("invalid")
+		Expect(err).To(HaveOccurred())
+		_, err = cfg.GetStringMap("list")
+		Expect(err).To(HaveOccurred())
+	})
+	It("Should return the default for missing and invalid values", func() {
+		Expect(cfg.GetIntOr("missing", 7)).To(Equal(7))
+		Expect(cfg.GetIntOr("invalid", 7)).To(Equal(7))
+		Expect(cfg.GetIntOr("int", 7)).To(Equal(42))
+		Expect(cfg.GetFloatOr("missing", 1.5)).To(Equal(1.5))
+		Expect(cfg.GetBoolOr("missing", true)).To(BeTrue())
+		Expect(cfg.GetDurationOr("missing", time.Second)).To(Equal(time.Second))
+		Expect(cfg.GetStringSliceOr("missing", []string{"x"})).To(Equal([]string{"x"}))
+		Expect(cfg.GetStringMapOr("missing", map[string]string{"x": "y"})).To(Equal(map[string]string{"x": "y"}))
+	})
+	It("Should work with any Configurable", func() {
+		memory := NewMemoryConfig()
+		memory.Set("port", "8080")
+		Expect(GetInt(memory, "port")).To(Equal(8080))
+		Expect(GetIntOr(memory, "missing", 80)).To(Equal(80))
+	})
+})
@@ -2,10 +2,9 @@
 package gonfig
 
 import (
-	"fmt"
-	"reflect"
-	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 )
 
 // The main Configurable interface
@@ -21,6 +20,13 @@ type Configurable interface {
 	All() map[string]string
 }
 
+// A Configurable that can tell an unset key from a key set to an empty string
+type LookupConfigurable interface {
+	Configurable
+	// Lookup a configuration variable, the bool reports whether the key is set
+	Lookup(string) (string, bool)
+}
+
 // A Configurable that can be loaded
 type ReadableConfig interface {
 	Configurable
@@ -47,17 +53,30 @@ type Config interface {
 
 // The Hierarchical Config that can be used to mount other configs that are searched for keys by Get
 type Gonfig struct {
-	// Overrides, these are checked before Configs are iterated for key
+	// Overrides, these are checked before the mounted Configs are iterated for key
 	Configurable
-	// named configurables, these are iterated if key is not found in Config
-	Configs map[string]Configurable
 	// Defaults configurable, if key is not found in the Configurable & Configurables in Config,
 	//Defaults is checked for fallback values
 	Defaults Configurable
+	// serializes changes to the mounts
+	mu sync.Mutex
+	// immutable snapshot of the named configurables in search order, these are iterated
+	// if key is not found in Config
+	mounts atomic.Pointer[[]*Mount]
+	// Gonfigs this Gonfig is mounted in
+	observers observers
+	// subscribers to changes
+	notifier notifier
+	// whether Get, Lookup and All interpolate references in values
+	interpolate atomic.Bool
 }
 
-// Ensure Gonfig implements Config
-var _ Config = (*Gonfig)(nil)
+// Ensure Gonfig implements Config, LookupConfigurable and ObservableConfigurable
+var (
+	_ Config                 = (*Gonfig)(nil)
+	_ LookupConfigurable     = (*Gonfig)(nil)
+	_ ObservableConfigurable = (*Gonfig)(nil)
+)
 
 // Creates a new config that is by default backed by a MemoryConfig Configurable
 // Takes optional initial configuration and an optional defaults
@@ -72,43 +91,11 @@ func NewConfig(initial Configurable, defaults ...Configurable) *Gonfig {
 	}
 
 	return &Gonfig{
-		initial,
-		make(map[string]Configurable),
-		defaults[0],
+		Configurable: initial,
+		Defaults:     defaults[0],
 	}
 }
 
-// Marshal current configuration hierarchy into target using gonfig:
-func (self *Gonfig) Marshal(target interface{}) error {
-	value := reflect.Indirect(reflect.ValueOf(target))
-	typ := value.Type()
-	for i := 0; i < typ.NumField(); i++ {
-		field := typ.Field(i)
-
-		v := strings.TrimSpace(self.Get(field.Tag.Get("gonfig")))
-		if v == "" {
-			continue
-		}
-
-		// Set the appropriate type.
-		switch field.Type.Kind() {
-		case reflect.Bool:
-			value.Field(i).SetBool(v != "0" && v != "false")
-		case reflect.Int:
-			newValue, err := strconv.ParseInt(v, 10, 0)
-			if err != nil {
-				return fmt.Errorf("Parse error: %s: %s", field.Tag.Get("env"), err)
-			}
-			value.Field(i).SetInt(newValue)
-		case reflect.String:
-			value.Field(i).SetString(v)
-		case reflect.Slice:
-			value.Field(i).Set(reflect.ValueOf(trimsplit(v, ",")))
-		}
-	}
-	return nil
-}
-
 // Resets all configs with the provided data, if no data is provided empties all stores
 // Never touches the Defaults, to reset Defaults use Config.Defaults().Reset()
 func (self *Gonfig) Reset(datas ...map[string]string) {
@@ -116,56 +103,60 @@ func (self *Gonfig) Reset(datas ...map[string]string) {
 	if len(datas) > 0 {
 		data = datas[0]
 	}
-	for _, value := range self.Configs {
+	for _, mount := range self.snapshot() {
 		if data != nil {
-			value.Reset(data)
+			mount.Reset(data)
 		} else {
-			value.Reset()
+			mount.Reset()
 		}
 	}
 	self.Configurable.Reset(data)
 }
 
-// Use config as named config and return an already set and loaded config
-// mounts a new configuration in the hierarchy.
-// conf.Use("global", NewUrlConfig("http://host.com/config..json")).
-// conf.Use("local", NewFileConfig("./config.json"))
-// err := conf.Load();.
-// Then get variable from specific config.
-// conf.Use("global").Get("key").
-// or traverse the hierarchy and search for "key".
-// conf.Get("key").
-// conf.Use("name") returns a nil value for non existing config named "name".
-func (self *Gonfig) Use(name string, config ...Configurable) Configurable {
-	if self.Configs == nil {
-		self.Configs = make(map[string]Configurable)
-	}
-	if len(config) == 0 {
-		return self.Configs[name]
+// Gets the key from first store that it is found from, checks Defaults
+func (self *Gonfig) Get(key string) string {
+	value, _ := self.Lookup(key)
+	return value
+}
+
+// Looks up the key from first store that it is set in, checks Defaults.
+// Unlike Get a key explicitly set to "" in a store shadows the stores after it.
+// If interpolation is enabled references in the value are interpolated, see SetInterpolation.
+func (self *Gonfig) Lookup(key string) (string, bool) {
+	value, ok := self.lookup(key)
+	if ok && self.interpolate.Load() {
+		value, _ = self.expand(value, []string{key})
 	}
-	self.Configs[name] = config[0]
-	LoadConfig(self.Configs[name])
-	return self.Configs[name]
+	return value, ok
 }
 
-// Gets the key from first store that it is found from, checks Defaults
-func (self *Gonfig) Get(key string) string {
+// looks up the value of key as it is set in the first store that has it
+func (self *Gonfig) lookup(key string) (string, bool) {
 	// override from out values
-	if value := self.Configurable.Get(key); value != "" {
-		return value
+	if value, ok := LookupConfig(self.Configurable, key); ok {
+		return value, true
 	}
-	// go through all in insert order untill key is found
-	for _, config := range self.Configs {
-		if value := config.Get(key); value != "" {
-			return value
+	// go through all in search order untill key is found
+	for _, mount := range self.snapshot() {
+		if value, ok := LookupConfig(mount.Configurable, key); ok {
+			return value, true
 		}
 	}
 	// if not found check the defaults as fallback
-	if value := self.Defaults.Get(key); value != "" {
-		return value
-	}
+	return LookupConfig(self.Defaults, key)
+}
 
-	return ""
+// Lookup key from config, if config is a LookupConfigurable its Lookup is used,
+// otherwise the key is considered set when Get returns a non empty value.
+func LookupConfig(config Configurable, key string) (string, bool) {
+	switch t := config.(type) {
+	case LookupConfigurable:
+		return t.Lookup(key)
+	case nil:
+		return "", false
+	}
+	value := config.Get(key)
+	return value, value != ""
 }
 
 // Save config it is of type WritableConfig, otherwise does nothing.
@@ -182,8 +173,8 @@ func SaveConfig(config Configurable) error {
 
 // Saves all mounted configurations in the hierarchy that implement the WritableConfig interface
 func (self *Gonfig) Save() error {
-	for _, config := range self.Configs {
-		if err := SaveConfig(config); err != nil {
+	for _, mount := range self.snapshot() {
+		if err := SaveConfig(mount.Configurable); err != nil {
 			return err
 		}
 	}
@@ -202,13 +193,26 @@ func LoadConfig(config Configurable) error {
 }
 
 // calls Configurable.Load() on all Configurable objects in the hierarchy.
+// Returns a LoadErrors naming every mount that failed to load, missing sources
+// of optional mounts are not reported.
 func (self *Gonfig) Load() error {
-	LoadConfig(self.Configurable)
-	LoadConfig(self.Defaults)
-	for _, config := range self.Configs {
-		LoadConfig(config)
+	var errs LoadErrors
+	errs = errs.add(OverrideSource, LoadConfig(self.Configurable), false)
+	errs = errs.add(DefaultsSource, LoadConfig(self.Defaults), false)
+	for _, mount := range self.snapshot() {
+		errs = errs.add(mount.Name, mount.load(), mount.Optional)
 	}
-	return nil
+	return errs.err()
+}
+
+// Returns the errors recorded by the last Load of the mounts as a LoadErrors,
+// this includes the errors of loading configs when they were mounted with Use.
+func (self *Gonfig) Err() error {
+	var errs LoadErrors
+	for _, mount := range self.snapshot() {
+		errs = errs.add(mount.Name, mount.Err(), mount.Optional)
+	}
+	return errs.err()
 }
 
 // Returns a map of data from all Configurables in use
@@ -223,24 +227,22 @@ func (self *Gonfig) Load() error {
 // Config.Use("b".).Get("a") == "2".
 func (self *Gonfig) All() map[string]string {
 	values := make(map[string]string)
-	// put defaults in values
-	for key, value := range self.Defaults.All() {
-		if values[key] == "" {
-			values[key] = value
-		}
+	// overrides from self first, then mounts in search order and defaults last
+	sources := []Configurable{self.Configurable}
+	for _, mount := range self.snapshot() {
+		sources = append(sources, mount.Configurable)
 	}
-	// put config values on top of them
-	for _, config := range self.Configs {
+	sources = append(sources, self.Defaults)
+	for _, config := range sources {
 		for key, value := range config.All() {
-			if values[key] == "" {
+			if _, ok := values[key]; !ok {
 				values[key] = value
 			}
 		}
 	}
-	// put overrides from self on top of all
-	for key, value := range self.Configurable.All() {
-		if values[key] == "" {
-			values[key] = value
+	if self.interpolate.Load() {
+		for key, value := range values {
+			values[key], _ = self.expand(value, []string{key})
 		}
 	}
 	return values
@@ -4,6 +4,7 @@ import (
 	. "github.com/Nomon/gonfig"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+	"os"
 )
 
 var _ = Describe("Gonfig", func() {
@@ -76,6 +77,96 @@ var _ = Describe("Gonfig", func() {
 			}
 			Expect(i == 3).To(BeTrue())
 		})
+		Describe("Mount order", func() {
+			BeforeEach(func() {
+				for _, name := range []string{"argv", "env", "local"} {
+					cfg.Use(name, NewMemoryConfig())
+					cfg.Use(name).Set("key", name)
+				}
+			})
+			It("Should search mounts in the order they were mounted", func() {
+				Expect(cfg.Mounts()).To(Equal([]string{"argv", "env", "local"}))
+				for i := 0; i < 10; i++ {
+					Expect(cfg.Get("key")).To(Equal("argv"))
+					Expect(cfg.All()["key"]).To(Equal("argv"))
+				}
+			})
+			It("Should keep the position of a mount when it is replaced", func() {
+				cfg.Use("argv", NewMemoryConfig())
+				Expect(cfg.Mounts()).To(Equal([]string{"argv", "env", "local"}))
+				Expect(cfg.Get("key")).To(Equal("env"))
+			})
+			It("Should search higher priority mounts first", func() {
+				cfg.UsePriority("flags", 10, NewMemoryConfig()).Set("key", "flags")
+				cfg.UsePriority("fallback", -1, NewMemoryConfig()).Set("key", "fallback")
+				cfg.Use("global", NewMemoryConfig())
+				Expect(cfg.Mounts()).To(Equal([]string{"flags", "argv", "env", "local", "global", "fallback"}))
+				Expect(cfg.Get("key")).To(Equal("flags"))
+			})
+			It("Should mount before and after other mounts", func() {
+				cfg.UseBefore("first", "argv", NewMemoryConfig())
+				cfg.UseAfter("second", "env", NewMemoryConfig())
+				cfg.UseAfter("last", "nonexisting", NewMemoryConfig())
+				Expect(cfg.Mounts()).To(Equal([]string{"first", "argv", "env", "second", "local", "last"}))
+				cfg.Use("second").Set("key", "second")
+				cfg.UseBefore("local", "argv", cfg.Use("local"))
+				Expect(cfg.Mounts()).To(Equal([]string{"first", "local", "argv", "env", "second", "last"}))
+				Expect(cfg.Get("key")).To(Equal("local"))
+			})
+		})
+		It("Should let an empty value override defaults and lower mounts", func() {
+			cfg.Defaults.Set("test_var", "default")
+			cfg.Use("mem", NewMemoryConfig()).Set("test_var", "mounted")
+			cfg.Set("test_var", "")
+			value, ok := cfg.Lookup("test_var")
+			Expect(ok).To(BeTrue())
+			Expect(value).To(Equal(""))
+			Expect(cfg.Get("test_var")).To(Equal(""))
+			all := cfg.All()
+			Expect(all).To(HaveKeyWithValue("test_var", ""))
+			_, ok = cfg.Lookup("nonexisting")
+			Expect(ok).To(BeFalse())
+		})
+		Describe("Load errors", func() {
+			It("Should name every failing mount", func() {
+				cfg.Use("invalid", NewJsonConfig("./config_invalid.json"))
+				cfg.Use("valid", NewJsonConfig("./config_valid.json"))
+				cfg.Use("missing", NewJsonConfig("./config_nonexisting.json"))
+				err := cfg.Load()
+				Expect(err).To(HaveOccurred())
+				errs, ok := err.(LoadErrors)
+				Expect(ok).To(BeTrue())
+				Expect(errs).To(HaveLen(2))
+				Expect(errs[0].Path).To(Equal([]string{"invalid"}))
+				Expect(errs[1].Path).To(Equal([]string{"missing"}))
+				Expect(os.IsNotExist(errs[1].Err)).To(BeTrue())
+				Expect(err.Error()).To(ContainSubstring("mount missing:"))
+			})
+			It("Should record the load error of Use", func() {
+				cfg.Use("missing", NewJsonConfig("./config_nonexisting.json"))
+				Expect(cfg.Err()).To(HaveOccurred())
+				Expect(cfg.Configs()[0].Err()).To(HaveOccurred())
+			})
+			It("Should not fail for missing optional mounts", func() {
+				cfg.UseOptional("missing", NewJsonConfig("./config_nonexisting.json"))
+				Expect(cfg.Err()).ToNot(HaveOccurred())
+				Expect(cfg.Load()).ToNot(HaveOccurred())
+				cfg.UseOptional("invalid", NewJsonConfig("./config_invalid.json"))
+				Expect(cfg.Load()).To(HaveOccurred())
+				cfg.SetOptional("missing", false)
+				Expect(cfg.Load()).To(HaveLen(2))
+			})
+			It("Should prefix errors of nested hierarchies", func() {
+				nested := NewConfig(nil)
+				nested.Use("missing", NewJsonConfig("./config_nonexisting.json"))
+				cfg.Use("nested", nested)
+				err := cfg.Load()
+				Expect(err).To(HaveOccurred())
+				Expect(err.(LoadErrors)[0].Path).To(Equal([]string{"nested", "missing"}))
+				cfg.SetOptional("nested", true)
+				Expect(cfg.Load()).ToNot(HaveOccurred())
+			})
+		})
 		It("Should be able to use Config objects in the hierarchy", func() {
 			cfg.Use("test", NewConfig(nil))
 			cfg.Set("test_123", "321test")
@@ -0,0 +1,172 @@
+package gonfig
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// IniConfig is a WritableConfig backed by an INI file.
+// Keys in a [section] are prefixed with the section name, so "key" in [section] is found from "section:key",
+// keys before the first section are not prefixed. Lines starting with ; or # are comments,
+// keys and values are separated by = or : and lines indented deeper than a key continue its value,
+// so keys can be indented too.
+// Double quoted values are unquoted with Go escaping rules, Save quotes values that need it.
+type IniConfig struct {
+	FileConfig
+}
+
+// Returns a new WritableConfig backed by an INI file at path.
+// The file does not need to exist, if it does not exist the first Save call will create it.
+// Errors loading the file are not returned, use OpenIniConfig or the error from Load
+// or Gonfig.Use to detect a missing or invalid file.
+func NewIniConfig(path string, cfg ...Configurable) WritableConfig {
+	conf, _ := OpenIniConfig(path, cfg...)
+	return conf
+}
+
+// Returns a new WritableConfig backed by an INI file at path like NewIniConfig,
+// along with the error from loading the file.
+func OpenIniConfig(path string, cfg ...Configurable) (WritableConfig, error) {
+	conf := &IniConfig{FileConfig: openFile(path, cfg)}
+	return conf, LoadConfig(conf)
+}
+
+// Attempts to load the INI file at IniConfig.Path
+// and Set them into the underlaying Configurable
+func (self *IniConfig) Load() error {
+	data, err := ioutil.ReadFile(self.Path)
+	if err != nil {
+		return err
+	}
+	out, err := unmarshalIni(data)
+	if err != nil {
+		return err
+	}
+	self.Configurable.Reset(out)
+	return nil
+}
+
+// Attempts to save the configuration from the underlaying Configurable to the INI file at IniConfig.Path,
+// the part of a key before its last ":" is saved as the section of the key.
+// Keys that would not load back as the same key, like names with "=" or starting with "[", fail the Save.
+func (self *IniConfig) Save() error {
+	data, err := marshalIni(self.Configurable.All())
+	if err != nil {
+		return err
+	}
+	return writeFile(self.Path, data, 0)
+}
+
+// Reloads the config whenever the INI file changes, see Watcher
+func (self *IniConfig) Watch(interval time.Duration) *Watcher {
+	return watchFile(self, self.Path, interval)
+}
+
+func unmarshalIni(data []byte) (map[string]string, error) {
+	output := make(map[string]string)
+	section := ""
+	// the key the last value was set to and the indentation of its line, lines indented
+	// deeper than the key are continuation lines appended to its value
+	last := ""
+	lastIndent := 0
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for n := 1; scanner.Scan(); n++ {
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+		indent := len(line) - len(strings.TrimLeft(line, " \t"))
+		switch {
+		case trimmed == "" || trimmed[0] == ';' || trimmed[0] == '#':
+			continue
+		case trimmed[0] == '[':
+			if trimmed[len(trimmed)-1] != ']' {
+				return nil, fmt.Errorf("ini: line %d: unterminated section %q", n, trimmed)
+			}
+			section = strings.TrimSpace(trimmed[1 : len(trimmed)-1])
+			last = ""
+		case last != "" && indent > lastIndent:
+			output[last] += "\n" + trimmed
+		default:
+			i := strings.IndexAny(trimmed, "=:")
+			if i < 0 {
+				return nil, fmt.Errorf("ini: line %d: expected key = value, got %q", n, trimmed)
+			}
+			key := strings.TrimSpace(trimmed[:i])
+			if section != "" {
+				key = section + ":" + key
+			}
+			value := strings.TrimSpace(trimmed[i+1:])
+			if strings.HasPrefix(value, `"`) {
+				unquoted, err := strconv.Unquote(value)
+				if err != nil {
+					return nil, fmt.Errorf("ini: line %d: invalid quoted value %s", n, value)
+				}
+				value = unquoted
+			}
+			output[key] = value
+			last = key
+			lastIndent = indent
+		}
+	}
+	return output, scanner.Err()
+}
+
+func marshalIni(data map[string]string) ([]byte, error) {
+	sections := make(map[string][]string)
+	for key := range data {
+		section, name := "", key
+		if i := strings.LastIndex(key, ":"); i >= 0 {
+			section, name = key[:i], key[i+1:]
+		}
+		if !validIniName(name) || name != key && !validIniSection(section) {
+			return nil, fmt.Errorf("ini: key %q can not be saved", key)
+		}
+		sections[section] = append(sections[section], key)
+	}
+	names := make([]string, 0, len(sections))
+	for name := range sections {
+		names = append(names, name)
+	}
+	// keys without a section sort first and must be written before the first section
+	sort.Strings(names)
+
+	var buffer bytes.Buffer
+	for _, name := range names {
+		if name != "" {
+			if buffer.Len() > 0 {
+				buffer.WriteString("\n")
+			}
+			buffer.WriteString("[" + name + "]\n")
+		}
+		keys := sections[name]
+		sort.Strings(keys)
+		for _, key := range keys {
+			buffer.WriteString(strings.TrimPrefix(key[len(name):], ":") + " = " + quoteIni(data[key]) + "\n")
+		}
+	}
+	return buffer.Bytes(), nil
+}
+
+// reports whether name loads back as the same key name
+func validIniName(name string) bool {
+	return name != "" && name == strings.TrimSpace(name) && !strings.ContainsAny(name, "=:\n\r") &&
+		!strings.ContainsAny(name[:1], "[;#")
+}
+
+// reports whether section loads back as the same section name
+func validIniSection(section string) bool {
+	return section != "" && section == strings.TrimSpace(section) && !strings.ContainsAny(section, "\n\r")
+}
+
+// quotes value if it would not load back as is
+func quoteIni(value string) string {
+	if value != strings.TrimSpace(value) || strings.HasPrefix(value, `"`) || strings.ContainsAny(value, "\n\r") {
+		return strconv.Quote(value)
+	}
+	return value
+}
@@ -0,0 +1,90 @@
+package gonfig_test
+
+import (
+	. "github.com/Nomon/gonfig"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"io/ioutil"
+	"os"
+)
+
+var _ = Describe("IniConfig", func() {
+	var (
+		err error
+		cfg WritableConfig
+	)
+
+	BeforeEach(func() {
+		cfg, err = OpenIniConfig("./config_valid.ini")
+	})
+
+	It("Should not error", func() {
+		Expect(err).NotTo(HaveOccurred())
+	})
+	It("Should load keys before the first section without a prefix", func() {
+		Expect(cfg.Get("test")).To(Equal("123"))
+		Expect(cfg.Get("name")).To(Equal("gonfig"))
+	})
+	It("Should prefix keys with their section", func() {
+		Expect(cfg.Get("database:host")).To(Equal("localhost"))
+		Expect(cfg.Get("database:port")).To(Equal("5432"))
+		Expect(cfg.Get("double_nested.section:test_inner")).To(Equal("foo"))
+	})
+	It("Should join continuation lines and unquote values", func() {
+		Expect(cfg.Get("database:motd")).To(Equal("first line\nsecond line"))
+		Expect(cfg.Get("database:quoted")).To(Equal("  padded\tvalue "))
+	})
+	It("Should load indented keys and continue only deeper indented lines", func() {
+		path := "./config_test_indented.ini"
+		defer os.Remove(path)
+		document := "[core]\n\tbare = false\n\tname = x\n\tmotd = first\n\t\tsecond\n[user]\n  email = a@b\n"
+		Expect(ioutil.WriteFile(path, []byte(document), 0600)).To(Succeed())
+		cfg, err := OpenIniConfig(path)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(cfg.All()).To(Equal(map[string]string{
+			"core:bare":  "false",
+			"core:name":  "x",
+			"core:motd":  "first\nsecond",
+			"user:email": "a@b",
+		}))
+	})
+	It("Should start indented sections instead of continuing the previous value", func() {
+		path := "./config_test_indented.ini"
+		defer os.Remove(path)
+		Expect(ioutil.WriteFile(path, []byte("a = 1\n  [s]\n  b = 2\n"), 0600)).To(Succeed())
+		cfg, err := OpenIniConfig(path)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(cfg.All()).To(Equal(map[string]string{"a": "1", "s:b": "2"}))
+	})
+	It("Should skip comments", func() {
+		Expect(cfg.All()).To(HaveLen(7))
+	})
+	It("Should error for invalid files", func() {
+		cfg, err = OpenIniConfig("./config_invalid.ini")
+		Expect(err).To(HaveOccurred())
+		cfg, err = OpenIniConfig("./config_nonexisting.ini")
+		Expect(os.IsNotExist(err)).To(BeTrue())
+	})
+
+	Describe("Save", func() {
+		path := "./config_test.ini"
+		AfterEach(func() {
+			os.Remove(path)
+		})
+		It("Should save sections that load back to the same values", func() {
+			saved := NewIniConfig(path)
+			saved.Reset(cfg.All())
+			Expect(saved.Save()).To(Succeed())
+			Expect(NewIniConfig(path).All()).To(Equal(cfg.All()))
+		})
+		It("Should fail saving keys that do not load back", func() {
+			for _, key := range []string{"[x", "k=x", ";k", "#k", " k", "s:", ":k", "s\n:k"} {
+				saved := NewIniConfig(path)
+				saved.Reset(map[string]string{key: "v"})
+				Expect(saved.Save()).To(MatchError(ContainSubstring("can not be saved")), key)
+			}
+			_, err := os.Stat(path)
+			Expect(os.IsNotExist(err)).To(BeTrue())
+		})
+	})
+})
@@ -0,0 +1,111 @@
+package gonfig
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Enables or disables interpolation of references in the values returned by Get, Lookup and All.
+// With interpolation enabled values can reference other keys of the hierarchy with ${key},
+// environment variables with ${env:NAME} and either form can fall back to a default with
+// ${key:-fallback} when the reference is not set or empty. References are resolved across the whole
+// hierarchy and the values they resolve to are interpolated too. $${ is an escaped ${ that is kept literally.
+// References that are not set interpolate to "" and references that form a cycle are left as they are,
+// use Resolve to get the errors.
+func (self *Gonfig) SetInterpolation(enabled bool) {
+	if self.interpolate.Swap(enabled) != enabled {
+		self.changed()
+	}
+}
+
+// Returns the value of key with its references interpolated whether or not interpolation is enabled.
+// Returns a *KeyError wrapping ErrNotSet if key or a reference without a fallback is not set,
+// or ErrCycle if the references form a cycle.
+func (self *Gonfig) Resolve(key string) (string, error) {
+	value, ok := self.lookup(key)
+	if !ok {
+		return "", &KeyError{Key: key, Err: ErrNotSet}
+	}
+	expanded, err := self.expand(value, []string{key})
+	if err != nil {
+		return expanded, &KeyError{Key: key, Value: value, Err: err}
+	}
+	return expanded, nil
+}
+
+// interpolates the references in value, stack holds the keys being resolved to detect cycles.
+// Returns the first error but keeps interpolating the rest of the value.
+func (self *Gonfig) expand(value string, stack []string) (string, error) {
+	if !strings.Contains(value, "${") {
+		return value, nil
+	}
+	var buffer strings.Builder
+	var first error
+	for i := 0; i < len(value); {
+		if strings.HasPrefix(value[i:], "$${") {
+			buffer.WriteString("${")
+			i += 3
+			continue
+		}
+		if !strings.HasPrefix(value[i:], "${") {
+			buffer.WriteByte(value[i])
+			i++
+			continue
+		}
+		end := closingBrace(value, i+2)
+		if end < 0 {
+			buffer.WriteString(value[i:])
+			break
+		}
+		expanded, err := self.reference(value[i+2:end], stack)
+		if err != nil && first == nil {
+			first = err
+		}
+		buffer.WriteString(expanded)
+		i = end + 1
+	}
+	return buffer.String(), first
+}
+
+// resolves the reference inside ${}
+func (self *Gonfig) reference(reference string, stack []string) (string, error) {
+	name, fallback, hasFallback := strings.Cut(reference, ":-")
+	var value string
+	var ok bool
+	var err error
+	if env := strings.TrimPrefix(name, "env:"); env != name {
+		value, ok = os.LookupEnv(env)
+	} else {
+		if contains(stack, name) {
+			return "${" + reference + "}", fmt.Errorf("%w: %s -> %s", ErrCycle, strings.Join(stack, " -> "), name)
+		}
+		if value, ok = self.lookup(name); ok {
+			value, err = self.expand(value, append(stack[:len(stack):len(stack)], name))
+		}
+	}
+	if hasFallback && (!ok || value == "") {
+		return self.expand(fallback, stack)
+	}
+	if !ok {
+		return "", fmt.Errorf("${%s}: %w", name, ErrNotSet)
+	}
+	return value, err
+}
+
+// returns the index of the } closing the reference starting at start, nested references are skipped
+func closingBrace(value string, start int) int {
+	depth := 0
+	for i := start; i < len(value); i++ {
+		switch {
+		case strings.HasPrefix(value[i:], "${"):
+			depth++
+			i++
+		case value[i] == '}' && depth == 0:
+			return i
+		case value[i] == '}':
+			depth--
+		}
+	}
+	return -1
+}
@@ -0,0 +1,80 @@
+package gonfig_test
+
+import (
+	"errors"
+	"os"
+
+	. "github.com/Nomon/gonfig"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Interpolation", func() {
+	var cfg *Gonfig
+	BeforeEach(func() {
+		os.Setenv("GONFIG_INTERPOLATE_TEST", "/home/test")
+		cfg = NewConfig(nil)
+		cfg.Use("local", NewMemoryConfig()).Reset(map[string]string{
+			"base":     "/srv/${name}",
+			"logs":     "${base}/logs",
+			"db:host":  "localhost",
+			"db:url":   "postgres://${db:host}:${db:port:-5432}/${name}",
+			"home":     "${env:GONFIG_INTERPOLATE_TEST}/app",
+			"empty":    "",
+			"fallback": "${empty:-${db:host}}",
+			"missing":  "x${nothing}y",
+			"escaped":  "$${base} costs $5",
+			"cycle:a":  "${cycle:b}",
+			"cycle:b":  "a ${cycle:a}",
+			"unclosed": "${base",
+		})
+		cfg.Defaults.Set("name", "app")
+	})
+	AfterEach(func() {
+		os.Unsetenv("GONFIG_INTERPOLATE_TEST")
+	})
+	It("Should not interpolate unless enabled", func() {
+		Expect(cfg.Get("logs")).To(Equal("${base}/logs"))
+	})
+	Context("when enabled", func() {
+		BeforeEach(func() {
+			cfg.SetInterpolation(true)
+		})
+		It("Should interpolate references across the hierarchy", func() {
+			Expect(cfg.Get("logs")).To(Equal("/srv/app/logs"))
+			Expect(cfg.Get("db:url")).To(Equal("postgres://localhost:5432/app"))
+			Expect(cfg.All()["logs"]).To(Equal("/srv/app/logs"))
+		})
+		It("Should interpolate environment variables", func() {
+			Expect(cfg.Get("home")).To(Equal("/home/test/app"))
+		})
+		It("Should use fallbacks for unset and empty references", func() {
+			Expect(cfg.Get("fallback")).To(Equal("localhost"))
+			Expect(cfg.Get("missing")).To(Equal("xy"))
+		})
+		It("Should keep escaped references", func() {
+			Expect(cfg.Get("escaped")).To(Equal("${base} costs $5"))
+			Expect(cfg.Get("unclosed")).To(Equal("${base"))
+		})
+		It("Should leave cycles unresolved", func() {
+			Expect(cfg.Get("cycle:a")).To(Equal("a ${cycle:a}"))
+		})
+		It("Should notify subscribers of changed references", func() {
+			var changes []Change
+			cfg.Subscribe(func(c []Change) { changes = c }, "logs")
+			cfg.Use("local").Set("name", "other")
+			Expect(changes).To(Equal([]Change{{Key: "logs", Type: KeyChanged, Old: "/srv/app/logs", New: "/srv/other/logs"}}))
+		})
+	})
+	It("Should report errors from Resolve", func() {
+		Expect(cfg.Resolve("logs")).To(Equal("/srv/app/logs"))
+		_, err := cfg.Resolve("cycle:a")
+		Expect(errors.Is(err, ErrCycle)).To(BeTrue())
+		Expect(err).To(MatchError(ContainSubstring("cycle:a -> cycle:b -> cycle:a")))
+		_, err = cfg.Resolve("missing")
+		Expect(errors.Is(err, ErrNotSet)).To(BeTrue())
+		Expect(err).To(MatchError(ContainSubstring("${nothing}")))
+		_, err = cfg.Resolve("nothing")
+		Expect(errors.Is(err, ErrNotSet)).To(BeTrue())
+	})
+})
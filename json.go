@@ -4,16 +4,57 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
-	"strings"
 	"io/ioutil"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 )
 
+// JsonConfig is a WritableConfig backed by a json file.
+// Objects are flattened into ":" separated keys, arrays of values into comma separated values and
+// arrays of objects or arrays are flattened with the index of the element as a key segment, so
+// "servers": [{"name": "a"}] is found from "servers:0:name" and "matrix": [[1, 2]] from "matrix:0". The json types of the loaded values
+// are remembered, so Save writes back the nested objects, arrays, numbers, booleans and nulls.
 type JsonConfig struct {
 	Configurable
 	Path string
+	// Save values that were not loaded from the file as numbers and booleans when they parse as such,
+	// by default they are saved as strings.
+	InferTypes bool
+	// Number of backups Save keeps of the previous versions of the file, the most recent is saved
+	// as Path.1 and older ones as Path.2 up to Path.Backups. Use RestoreBackup to roll back to one.
+	Backups int
+	// Indentation of the saved json, Save writes compact json if Indent is empty.
+	// Object keys are always saved in sorted order so saves of the same values are identical.
+	Indent string
+	// Save only the keys that changed to the existing file, keeping the order of its keys, the way its
+	// numbers are written and the content that is not loaded into the config, like empty objects.
+	// The whole file is written again with a single indentation, Indent or the one detected from the file,
+	// so sections that were formatted differently, like inline arrays, are reformatted.
+	Merge bool
+	// json types of the loaded keys
+	types MemoryConfig
 }
 
-func unmarshalJsonSegment(jsonSegment map[string]interface{}, segmentPath string, output map[string]string) {
+// the json types of flattened values
+const (
+	jsonString  = "string"
+	jsonNumber  = "number"
+	jsonBoolean = "boolean"
+	jsonNull    = "null"
+	// array of values, the type of the elements follows the prefix, it is empty if the types are mixed
+	jsonArray = "array:"
+	// array of objects or arrays, set for the key of the array, its elements are flattened with their index
+	// as a key segment
+	jsonObjects = "objects"
+)
+
+var jsonNumberPattern = regexp.MustCompile(`^-?(0|[1-9][0-9]*)(\.[0-9]+)?([eE][+-]?[0-9]+)?$`)
+
+func unmarshalJsonSegment(jsonSegment map[string]interface{}, segmentPath string, output, types map[string]string) {
 	if segmentPath != "" {
 		segmentPath += ":"
 	}
@@ -23,43 +64,148 @@ func unmarshalJsonSegment(jsonSegment map[string]interface{}, segmentPath string
 
 		switch v := v.(type) {
 		case map[string]interface{}:
-			unmarshalJsonSegment(v, keyWithPath, output)
+			unmarshalJsonSegment(v, keyWithPath, output, types)
 		case []interface{}:
-			var buffer bytes.Buffer
-			for _, sVal := range v {
-				buffer.WriteString(fmt.Sprintf("%v,", sVal))
+			if indexed, ok := jsonIndexed(v); ok {
+				types[keyWithPath] = jsonObjects
+				unmarshalJsonSegment(indexed, keyWithPath, output, types)
+				continue
 			}
-			output[keyWithPath] = strings.Trim(buffer.String(), ",")
+			values := make([]string, len(v))
+			typ := ""
+			for i, sVal := range v {
+				values[i] = formatJson(sVal)
+				if i == 0 || typ == jsonType(sVal) {
+					typ = jsonType(sVal)
+				} else {
+					typ = ""
+				}
+			}
+			output[keyWithPath] = strings.Join(values, ",")
+			types[keyWithPath] = jsonArray + typ
 		default:
-			output[keyWithPath] = fmt.Sprintf("%v", v)
+			output[keyWithPath] = formatJson(v)
+			types[keyWithPath] = jsonType(v)
 		}
 	}
 }
 
-func unmarshalJson(bytes []byte) (map[string]string, error) {
+// flattens a json document, returns the flattened values and their json types
+func unmarshalJson(data []byte) (map[string]string, map[string]string, error) {
 	out := make(map[string]interface{})
-	if err := json.Unmarshal(bytes, &out); err != nil {
-		return nil, err
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	// keep numbers as they are written instead of converting them to float64
+	decoder.UseNumber()
+	if err := decoder.Decode(&out); err != nil {
+		return nil, nil, err
 	}
 
-	var output map[string]string = make(map[string]string)
-	unmarshalJsonSegment(out, "", output)
+	output := make(map[string]string)
+	types := make(map[string]string)
+	unmarshalJsonSegment(out, "", output, types)
 
-	return output, nil
+	return output, types, nil
+}
+
+// returns the elements of an array that holds objects or arrays keyed by their index,
+// ok is false if values holds only scalars and can be flattened into a comma separated value
+func jsonIndexed(values []interface{}) (indexed map[string]interface{}, ok bool) {
+	for _, value := range values {
+		switch value.(type) {
+		case map[string]interface{}, []interface{}:
+			ok = true
+		}
+	}
+	if !ok {
+		return nil, false
+	}
+	indexed = make(map[string]interface{}, len(values))
+	for i, value := range values {
+		indexed[strconv.Itoa(i)] = value
+	}
+	return indexed, true
+}
+
+// formats a decoded json value as a string, null is formatted as ""
+func formatJson(value interface{}) string {
+	if value == nil {
+		return ""
+	}
+	return fmt.Sprintf("%v", value)
+}
+
+// returns the json type of a decoded value
+func jsonType(value interface{}) string {
+	switch value.(type) {
+	case json.Number:
+		return jsonNumber
+	case bool:
+		return jsonBoolean
+	case nil:
+		return jsonNull
+	}
+	return jsonString
+}
+
+// converts a flattened value back to a value of the json type typ, values of unknown type are inferred
+// if infer is true and kept as strings otherwise. Values that do not parse as typ are kept as strings.
+func jsonValue(value, typ string, infer bool) interface{} {
+	if strings.HasPrefix(typ, jsonArray) {
+		values := []interface{}{}
+		if value == "" {
+			return values
+		}
+		elem := strings.TrimPrefix(typ, jsonArray)
+		for _, v := range strings.Split(value, ",") {
+			// elements of mixed arrays are inferred
+			values = append(values, jsonValue(v, elem, infer || elem == ""))
+		}
+		return values
+	}
+	switch typ {
+	case jsonNumber:
+		if jsonNumberPattern.MatchString(value) {
+			return json.Number(value)
+		}
+	case jsonBoolean:
+		if b, err := strconv.ParseBool(value); err == nil {
+			return b
+		}
+	case jsonNull:
+		if value == "" {
+			return nil
+		}
+	case "":
+		if !infer {
+			break
+		}
+		if value == "true" || value == "false" {
+			return value == "true"
+		}
+		if jsonNumberPattern.MatchString(value) {
+			return json.Number(value)
+		}
+	}
+	return value
 }
 
 // Returns a new WritableConfig backed by a json file at path.
 // The file does not need to exist, if it does not exist the first Save call will create it.
+// Errors loading the file are not returned, use OpenJsonConfig or the error from Load
+// or Gonfig.Use to detect a missing or invalid file.
 func NewJsonConfig(path string, cfg ...Configurable) WritableConfig {
-	if len(cfg) == 0 {
-		cfg = append(cfg, NewMemoryConfig())
-	}
-	LoadConfig(cfg[0])
-	conf := &JsonConfig{cfg[0], path}
-	LoadConfig(conf)
+	conf, _ := OpenJsonConfig(path, cfg...)
 	return conf
 }
 
+// Returns a new WritableConfig backed by a json file at path like NewJsonConfig,
+// along with the error from loading the file. The returned config is usable even if the file
+// failed to load, os.IsNotExist(err) reports whether the file is missing.
+func OpenJsonConfig(path string, cfg ...Configurable) (WritableConfig, error) {
+	conf := &JsonConfig{Configurable: backingConfig(cfg), Path: path}
+	return conf, LoadConfig(conf)
+}
+
 // Attempts to load the json configuration at JsonConfig.Path
 // and Set them into the underlaying Configurable
 func (self *JsonConfig) Load() (err error) {
@@ -67,25 +213,318 @@ func (self *JsonConfig) Load() (err error) {
 	if data, err = ioutil.ReadFile(self.Path); err != nil {
 		return err
 	}
-	out, err := unmarshalJson(data)
+	out, types, err := unmarshalJson(data)
 	if err != nil {
 		return err
 	}
 
+	self.types.Reset(types)
 	self.Configurable.Reset(out)
 	return nil
 }
 
-// Attempts to save the configuration from the underlaying Configurable to json file at JsonConfig.Path
+// Attempts to save the configuration from the underlaying Configurable to json file at JsonConfig.Path,
+// ":" separated keys are saved as nested objects and loaded values keep their json types.
+// The file is replaced atomically keeping its mode and owner, so a failed Save never leaves a partially written file.
 func (self *JsonConfig) Save() (err error) {
-	b, err := json.Marshal(self.Configurable.All())
+	var b []byte
+	if self.Merge {
+		b, err = self.merge()
+	} else {
+		b, err = encodeJson(self.document(), self.Indent)
+	}
 	if err != nil {
 		return err
 	}
+	if self.Indent != "" && !bytes.HasSuffix(b, []byte("\n")) {
+		b = append(b, '\n')
+	}
 
-	if err := ioutil.WriteFile(self.Path, b, 0600); err != nil {
+	if err := writeFile(self.Path, b, self.Backups); err != nil {
 		return err
 	}
 
 	return nil
 }
+
+// returns the values of the config nested back to a json document
+func (self *JsonConfig) document() map[string]interface{} {
+	types := self.types.All()
+	nested := nestKeys(self.Configurable.All(), func(key, value string) interface{} {
+		return jsonValue(value, types[key], self.InferTypes)
+	})
+	arraysFromIndexes(nested, types, jsonObjects)
+	return nested
+}
+
+// returns the json file at Path with the keys that differ from the config updated, the keys missing
+// from the config removed and everything else kept in the order of the file, re-encoded with the
+// indentation of its first indented line
+func (self *JsonConfig) merge() ([]byte, error) {
+	data, err := ioutil.ReadFile(self.Path)
+	if os.IsNotExist(err) {
+		return encodeJson(self.document(), self.Indent)
+	}
+	if err != nil {
+		return nil, err
+	}
+	document, err := decodeJsonDocument(data)
+	if err != nil {
+		return nil, err
+	}
+	existing, types, err := unmarshalJson(data)
+	if err != nil {
+		return nil, err
+	}
+	loaded := self.types.All()
+	current := self.Configurable.All()
+	keys := make([]string, 0, len(current))
+	for key := range current {
+		keys = append(keys, key)
+	}
+	// new keys are appended in sorted order
+	sort.Strings(keys)
+	for _, key := range keys {
+		value := current[key]
+		if old, ok := existing[key]; ok && old == value {
+			continue
+		}
+		typ, ok := types[key]
+		if !ok {
+			typ = loaded[key]
+		}
+		setJsonValue(document, strings.Split(key, ":"), jsonValue(value, typ, self.InferTypes))
+	}
+	for key := range existing {
+		if _, ok := current[key]; !ok {
+			removeJsonValue(document, strings.Split(key, ":"))
+		}
+	}
+	indent := self.Indent
+	if indent == "" {
+		indent = jsonIndent(data)
+	}
+	b, err := encodeJson(document, indent)
+	if err != nil || !bytes.HasSuffix(data, []byte("\n")) {
+		return b, err
+	}
+	return append(b, '\n'), nil
+}
+
+// Rolls the json file at Path back to the nth most recent backup kept by Save and loads it,
+// RestoreBackup(1) restores the file as it was before the last Save.
+func (self *JsonConfig) RestoreBackup(n int) error {
+	if err := restoreBackup(self.Path, n); err != nil {
+		return err
+	}
+	return self.Load()
+}
+
+// Lookup key from the underlaying Configurable
+func (self *JsonConfig) Lookup(key string) (string, bool) {
+	return LookupConfig(self.Configurable, key)
+}
+
+// Observe changes to the underlaying Configurable
+func (self *JsonConfig) Observe(fn func()) func() {
+	return ObserveConfig(self.Configurable, fn)
+}
+
+// Returns the Path of the json file for keys set in the config
+func (self *JsonConfig) Origin(key string) string {
+	if _, ok := self.Lookup(key); ok {
+		return self.Path
+	}
+	return ""
+}
+
+// Reloads the config whenever the json file changes, see Watcher
+func (self *JsonConfig) Watch(interval time.Duration) *Watcher {
+	return watchFile(self, self.Path, interval)
+}
+
+// an object of a json document that keeps the order of its keys
+type jsonObject struct {
+	keys   []string
+	values map[string]interface{}
+}
+
+func newJsonObject() *jsonObject {
+	return &jsonObject{values: make(map[string]interface{})}
+}
+
+func (self *jsonObject) set(key string, value interface{}) {
+	if _, ok := self.values[key]; !ok {
+		self.keys = append(self.keys, key)
+	}
+	self.values[key] = value
+}
+
+func (self *jsonObject) remove(key string) {
+	if _, ok := self.values[key]; !ok {
+		return
+	}
+	delete(self.values, key)
+	for i, k := range self.keys {
+		if k == key {
+			self.keys = append(self.keys[:i], self.keys[i+1:]...)
+			break
+		}
+	}
+}
+
+// writes the object with its keys in order
+func (self *jsonObject) MarshalJSON() ([]byte, error) {
+	var buffer bytes.Buffer
+	buffer.WriteByte('{')
+	for i, key := range self.keys {
+		if i > 0 {
+			buffer.WriteByte(',')
+		}
+		k, err := encodeJson(key, "")
+		if err != nil {
+			return nil, err
+		}
+		v, err := encodeJson(self.values[key], "")
+		if err != nil {
+			return nil, err
+		}
+		buffer.Write(k)
+		buffer.WriteByte(':')
+		buffer.Write(v)
+	}
+	buffer.WriteByte('}')
+	return buffer.Bytes(), nil
+}
+
+// decodes a json document keeping the order of the keys of its objects
+func decodeJsonDocument(data []byte) (*jsonObject, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	value, err := decodeJsonValue(decoder)
+	if err != nil {
+		return nil, err
+	}
+	document, ok := value.(*jsonObject)
+	if !ok {
+		return nil, fmt.Errorf("json: document root is not an object")
+	}
+	return document, nil
+}
+
+func decodeJsonValue(decoder *json.Decoder) (interface{}, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch token {
+	case json.Delim('{'):
+		object := newJsonObject()
+		for decoder.More() {
+			key, err := decoder.Token()
+			if err != nil {
+				return nil, err
+			}
+			value, err := decodeJsonValue(decoder)
+			if err != nil {
+				return nil, err
+			}
+			object.set(key.(string), value)
+		}
+		_, err = decoder.Token()
+		return object, err
+	case json.Delim('['):
+		array := []interface{}{}
+		for decoder.More() {
+			value, err := decodeJsonValue(decoder)
+			if err != nil {
+				return nil, err
+			}
+			array = append(array, value)
+		}
+		_, err = decoder.Token()
+		return array, err
+	}
+	return token, nil
+}
+
+// sets the value at the path of a flattened key in node, returns the updated node and false if node can not hold path.
+// Keys whose path is blocked by a value are set flat like nestKeys does.
+func setJsonValue(node interface{}, path []string, value interface{}) (interface{}, bool) {
+	switch node := node.(type) {
+	case *jsonObject:
+		if len(path) == 1 {
+			node.set(path[0], value)
+			return node, true
+		}
+		child, ok := node.values[path[0]]
+		if !ok {
+			child = newJsonObject()
+		}
+		if updated, ok := setJsonValue(child, path[1:], value); ok {
+			node.set(path[0], updated)
+			return node, true
+		}
+		node.set(strings.Join(path, ":"), value)
+		return node, true
+	case []interface{}:
+		index, err := strconv.Atoi(path[0])
+		if err != nil || index < 0 || index > len(node) {
+			return node, false
+		}
+		if index == len(node) {
+			node = append(node, newJsonObject())
+		}
+		if len(path) == 1 {
+			node[index] = value
+			return node, true
+		}
+		updated, ok := setJsonValue(node[index], path[1:], value)
+		if !ok {
+			return node, false
+		}
+		node[index] = updated
+		return node, true
+	}
+	return node, false
+}
+
+// removes the value at the path of a flattened key from node
+func removeJsonValue(node interface{}, path []string) {
+	switch node := node.(type) {
+	case *jsonObject:
+		if _, ok := node.values[strings.Join(path, ":")]; ok || len(path) == 1 {
+			node.remove(strings.Join(path, ":"))
+			return
+		}
+		removeJsonValue(node.values[path[0]], path[1:])
+	case []interface{}:
+		if index, err := strconv.Atoi(path[0]); err == nil && index >= 0 && index < len(node) && len(path) > 1 {
+			removeJsonValue(node[index], path[1:])
+		}
+	}
+}
+
+// returns the indentation of the first indented line of data, "" for compact json
+func jsonIndent(data []byte) string {
+	for _, line := range strings.Split(string(data), "\n")[1:] {
+		if indent := line[:len(line)-len(strings.TrimLeft(line, " \t"))]; indent != "" {
+			return indent
+		}
+	}
+	return ""
+}
+
+// encodes value as json without escaping html characters, indented with indent if it is set
+func encodeJson(value interface{}, indent string) ([]byte, error) {
+	var buffer bytes.Buffer
+	encoder := json.NewEncoder(&buffer)
+	encoder.SetEscapeHTML(false)
+	if indent != "" {
+		encoder.SetIndent("", indent)
+	}
+	if err := encoder.Encode(value); err != nil {
+		return nil, err
+	}
+	return bytes.TrimRight(buffer.Bytes(), "\n"), nil
+}
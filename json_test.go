@@ -4,6 +4,9 @@ import (
 	. "github.com/Nomon/gonfig"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 )
 
 var _ = Describe("JsonConfig", func() {
@@ -78,6 +81,18 @@ var _ = Describe("JsonConfig", func() {
 		})
 	})
 
+	Describe("OpenJsonConfig", func() {
+		It("Should return the load error with a functional config", func() {
+			cfg, err := OpenJsonConfig("./config_nonexisting.json")
+			Expect(os.IsNotExist(err)).To(BeTrue())
+			cfg.Set("QQ", "123")
+			Expect(cfg.Get("QQ")).To(Equal("123"))
+			cfg, err = OpenJsonConfig("./config_valid.json")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(cfg.Get("test")).To(Equal("123"))
+		})
+	})
+
 	Describe("Config conversion", func() {
 		It("Should be possible ro construct new JSON config from a gonfig hierarchy", func() {
 			cfg := NewConfig(nil)
@@ -85,7 +100,7 @@ var _ = Describe("JsonConfig", func() {
 			cfg.Use("config_b", NewMemoryConfig())
 			cfg.Use("config_a").Set("config_a_var_a", "conf_a")
 			cfg.Use("config_b").Set("config_b_var_a", "conf_b")
-			jsonConf := &JsonConfig{cfg, "./config.json"}
+			jsonConf := &JsonConfig{Configurable: cfg, Path: "./config.json"}
 			err := jsonConf.Save()
 			Expect(err).ToNot(HaveOccurred())
 			jsonConf2 := NewJsonConfig("./config.json", cfg)
@@ -94,6 +109,197 @@ var _ = Describe("JsonConfig", func() {
 		})
 	})
 
+	Describe("Save", func() {
+		path := "./config_test_nested.json"
+		AfterEach(func() {
+			os.Remove(path)
+		})
+		It("Should preserve the shape of the loaded file", func() {
+			cfg, err := OpenJsonConfig("./config_nested.json")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(cfg.Get("version")).To(Equal("1.10"))
+			Expect(cfg.Get("big")).To(Equal("10000000"))
+			Expect(cfg.Get("db:replicas:1:host")).To(Equal("r2"))
+			Expect(cfg.Get("matrix:0")).To(Equal("1,2"))
+			Expect(cfg.Get("cells:0:0")).To(Equal("a,b"))
+			Expect(cfg.Get("cells:1:0:x")).To(Equal("1"))
+			Expect(cfg.Get("cells:1:1")).To(Equal("c"))
+			saved := cfg.(*JsonConfig)
+			saved.Path = path
+			Expect(saved.Save()).To(Succeed())
+			original, err := ioutil.ReadFile("./config_nested.json")
+			Expect(err).ToNot(HaveOccurred())
+			data, err := ioutil.ReadFile(path)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(data).To(MatchJSON(original))
+			Expect(NewJsonConfig(path).All()).To(Equal(cfg.All()))
+		})
+		It("Should save new keys as nested strings", func() {
+			cfg := NewJsonConfig(path)
+			cfg.Set("db:host", "localhost")
+			cfg.Set("db:port", "5432")
+			Expect(cfg.Save()).To(Succeed())
+			data, err := ioutil.ReadFile(path)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(data).To(MatchJSON(`{"db":{"host":"localhost","port":"5432"}}`))
+		})
+		It("Should infer numbers and booleans of new keys when enabled", func() {
+			cfg := &JsonConfig{Configurable: NewMemoryConfig(), Path: path, InferTypes: true}
+			cfg.Set("db:port", "5432")
+			cfg.Set("db:ssl", "true")
+			cfg.Set("db:zip", "007")
+			cfg.Set("db:host", "localhost")
+			Expect(cfg.Save()).To(Succeed())
+			data, err := ioutil.ReadFile(path)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(data).To(MatchJSON(`{"db":{"host":"localhost","port":5432,"ssl":true,"zip":"007"}}`))
+		})
+	})
+
+	Describe("Atomic Save", func() {
+		path := "./config_test_atomic.json"
+		AfterEach(func() {
+			files, _ := filepath.Glob(path + "*")
+			for _, file := range files {
+				os.Remove(file)
+			}
+		})
+		It("Should keep the mode of the existing file and leave no temporary files", func() {
+			Expect(ioutil.WriteFile(path, []byte(`{"a":"1"}`), 0640)).To(Succeed())
+			Expect(os.Chmod(path, 0640)).To(Succeed())
+			cfg := NewJsonConfig(path)
+			cfg.Set("a", "2")
+			Expect(cfg.Save()).To(Succeed())
+			info, err := os.Stat(path)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(info.Mode().Perm()).To(Equal(os.FileMode(0640)))
+			files, _ := filepath.Glob(".config_test_atomic.json.tmp*")
+			Expect(files).To(BeEmpty())
+			Expect(NewJsonConfig(path).Get("a")).To(Equal("2"))
+		})
+		It("Should create new files with mode 0600", func() {
+			Expect(NewJsonConfig(path).Save()).To(Succeed())
+			info, err := os.Stat(path)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(info.Mode().Perm()).To(Equal(os.FileMode(0600)))
+		})
+		It("Should write through symlinks", func() {
+			target := path + ".target"
+			Expect(ioutil.WriteFile(target, []byte(`{"a":"1"}`), 0600)).To(Succeed())
+			Expect(os.Symlink(filepath.Base(target), path)).To(Succeed())
+			cfg := NewJsonConfig(path)
+			cfg.Set("a", "2")
+			Expect(cfg.Save()).To(Succeed())
+			info, err := os.Lstat(path)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(info.Mode() & os.ModeSymlink).ToNot(BeZero())
+			Expect(NewJsonConfig(target).Get("a")).To(Equal("2"))
+		})
+		It("Should rotate backups and restore them", func() {
+			cfg := &JsonConfig{Configurable: NewMemoryConfig(), Path: path, Backups: 2}
+			for _, version := range []string{"1", "2", "3", "4"} {
+				cfg.Set("version", version)
+				Expect(cfg.Save()).To(Succeed())
+			}
+			Expect(NewJsonConfig(path + ".1").Get("version")).To(Equal("3"))
+			Expect(NewJsonConfig(path + ".2").Get("version")).To(Equal("2"))
+			_, err := os.Stat(path + ".3")
+			Expect(os.IsNotExist(err)).To(BeTrue())
+
+			Expect(cfg.RestoreBackup(2)).To(Succeed())
+			Expect(cfg.Get("version")).To(Equal("2"))
+			Expect(NewJsonConfig(path).Get("version")).To(Equal("2"))
+			Expect(NewJsonConfig(path + ".1").Get("version")).To(Equal("3"))
+
+			Expect(os.IsNotExist(cfg.RestoreBackup(3))).To(BeTrue())
+			Expect(cfg.RestoreBackup(0)).ToNot(Succeed())
+		})
+	})
+
+	Describe("Formatted Save", func() {
+		path := "./config_test_format.json"
+		AfterEach(func() {
+			os.Remove(path)
+		})
+		It("Should pretty print with sorted keys", func() {
+			cfg := &JsonConfig{Configurable: NewMemoryConfig(), Path: path, Indent: "  "}
+			cfg.Set("b", "2")
+			cfg.Set("a:y", "<&>")
+			cfg.Set("a:x", "1")
+			Expect(cfg.Save()).To(Succeed())
+			data, err := ioutil.ReadFile(path)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(data)).To(Equal("{\n  \"a\": {\n    \"x\": \"1\",\n    \"y\": \"<&>\"\n  },\n  \"b\": \"2\"\n}\n"))
+		})
+		It("Should merge changes into the existing document", func() {
+			original := "{\n    \"name\": \"app\",\n    \"db\": {\n        \"port\": 5432,\n        \"host\": \"localhost\",\n        \"user\": \"admin\"\n    },\n    \"empty\": {},\n    \"servers\": [{\"name\": \"a\"}, {\"name\": \"b\"}],\n    \"price\": 1.50\n}\n"
+			Expect(ioutil.WriteFile(path, []byte(original), 0600)).To(Succeed())
+			cfg, err := OpenJsonConfig(path)
+			Expect(err).ToNot(HaveOccurred())
+			json := cfg.(*JsonConfig)
+			json.Merge = true
+			json.Set("db:host", "remote")
+			json.Set("servers:1:name", "c")
+			json.Set("servers:2:name", "d")
+			json.Set("cache:ttl", "60")
+			json.Set("zone", "eu")
+			json.Configurable.Reset(func() map[string]string {
+				values := json.All()
+				delete(values, "db:user")
+				return values
+			}())
+			Expect(json.Save()).To(Succeed())
+			data, err := ioutil.ReadFile(path)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(data)).To(Equal(`{
+    "name": "app",
+    "db": {
+        "port": 5432,
+        "host": "remote"
+    },
+    "empty": {},
+    "servers": [
+        {
+            "name": "a"
+        },
+        {
+            "name": "c"
+        },
+        {
+            "name": "d"
+        }
+    ],
+    "price": 1.50,
+    "cache": {
+        "ttl": "60"
+    },
+    "zone": "eu"
+}
+`))
+		})
+		It("Should reformat untouched sections with the indentation of the file", func() {
+			original := "{\n  \"o\": [{\"x\": 1}, {\"x\": 2}],\n  \"a\": 1\n}\n"
+			Expect(ioutil.WriteFile(path, []byte(original), 0600)).To(Succeed())
+			cfg, err := OpenJsonConfig(path)
+			Expect(err).ToNot(HaveOccurred())
+			json := cfg.(*JsonConfig)
+			json.Merge = true
+			json.Set("a", "2")
+			Expect(json.Save()).To(Succeed())
+			data, err := ioutil.ReadFile(path)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(data)).To(Equal("{\n  \"o\": [\n    {\n      \"x\": 1\n    },\n    {\n      \"x\": 2\n    }\n  ],\n  \"a\": 2\n}\n"))
+		})
+		It("Should merge into a new file", func() {
+			cfg := &JsonConfig{Configurable: NewMemoryConfig(), Path: path, Merge: true}
+			cfg.Set("a", "1")
+			Expect(cfg.Save()).To(Succeed())
+			data, err := ioutil.ReadFile(path)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(data)).To(Equal(`{"a":"1"}`))
+		})
+	})
+
 	Describe("Namespacing", func() {
 
 	})
@@ -0,0 +1,102 @@
+package gonfig
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// rebuilds nested maps from ":" separated keys, value converts the flat values into the values of the nested maps.
+// If a key is both a value and a prefix of other keys the longer keys are kept flat.
+func nestKeys(flat map[string]string, value func(key, value string) interface{}) map[string]interface{} {
+	keys := make([]string, 0, len(flat))
+	for key := range flat {
+		keys = append(keys, key)
+	}
+	// prefixes sort before the keys they prefix, so values are placed before the keys they conflict with
+	sort.Strings(keys)
+	nested := make(map[string]interface{})
+	for _, key := range keys {
+		v := value(key, flat[key])
+		parts := strings.Split(key, ":")
+		segment := nested
+		for _, part := range parts[:len(parts)-1] {
+			if segment[part] == nil {
+				segment[part] = make(map[string]interface{})
+			}
+			next, ok := segment[part].(map[string]interface{})
+			if !ok {
+				segment = nil
+				break
+			}
+			segment = next
+		}
+		if segment == nil {
+			nested[key] = v
+		} else {
+			segment[parts[len(parts)-1]] = v
+		}
+	}
+	return nested
+}
+
+// turns the elements nested by their index under the keys of type marker in types back into arrays,
+// marker is the type the flattener set for arrays of objects or arrays
+func arraysFromIndexes(nested map[string]interface{}, types map[string]string, marker string) {
+	keys := make([]string, 0)
+	for key, typ := range types {
+		if typ == marker {
+			keys = append(keys, key)
+		}
+	}
+	// convert the deepest arrays first so their parents still see them keyed by index
+	sort.Sort(sort.Reverse(sort.StringSlice(keys)))
+	for _, key := range keys {
+		tablesToArray(nested, strings.Split(key, ":"))
+	}
+}
+
+// replaces the table keyed by index at path in nested with an array of its values ordered by index
+func tablesToArray(nested map[string]interface{}, path []string) {
+	segment := nested
+	for _, part := range path[:len(path)-1] {
+		next, ok := segment[part].(map[string]interface{})
+		if !ok {
+			return
+		}
+		segment = next
+	}
+	key := path[len(path)-1]
+	indexed, ok := segment[key].(map[string]interface{})
+	if !ok {
+		return
+	}
+	indexes := make([]int, 0, len(indexed))
+	tables := true
+	for index := range indexed {
+		i, err := strconv.Atoi(index)
+		if err != nil {
+			// not an array anymore
+			return
+		}
+		if _, ok := indexed[index].(map[string]interface{}); !ok {
+			tables = false
+		}
+		indexes = append(indexes, i)
+	}
+	sort.Ints(indexes)
+	values := make([]interface{}, len(indexes))
+	for i, index := range indexes {
+		values[i] = indexed[strconv.Itoa(index)]
+	}
+	if !tables {
+		segment[key] = values
+		return
+	}
+	// arrays of tables are kept as []map[string]interface{} so toml saves them as [[key]] tables
+	maps := make([]map[string]interface{}, len(values))
+	for i, value := range values {
+		maps[i] = value.(map[string]interface{})
+	}
+	segment[key] = maps
+}
@@ -0,0 +1,379 @@
+package gonfig
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	durationType        = reflect.TypeOf(time.Duration(0))
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// Marshal current configuration hierarchy into target using gonfig: struct tags, see MarshalConfig.
+func (self *Gonfig) Marshal(target interface{}) error {
+	return MarshalConfig(self, target)
+}
+
+// Marshal the configuration in config into the struct pointed to by target.
+// Fields are decoded from the key in their gonfig tag, fields without a tag or tagged "-" are skipped.
+// Nested struct fields decode their fields from keys prefixed by the key of the struct and ":",
+// embedded structs without a tag decode their fields without a prefix.
+// Values are decoded with the rules of the typed getters: bools, ints, uints, floats, strings,
+// time.Duration and encoding.TextUnmarshaler values are parsed from the trimmed value of the key,
+// slices from comma separated values and maps from comma separated name=value pairs or the keys nested
+// under the key of the map. Slices of structs decode each struct from the keys nested under its index,
+// so "servers:0:name" is the Name of the first server. Pointers are allocated when their key is set.
+// Keys that are not set leave the fields as they are unless the field has a default:"value" tag,
+// fields tagged required:"true" must be set in some layer or have a default.
+// Decoded values are validated against the min, max, oneof and regex tags, see validate.
+// All the fields that fail to decode or validate are returned as MarshalErrors of *KeyError naming the key and field.
+func MarshalConfig(config Configurable, target interface{}) error {
+	value := reflect.ValueOf(target)
+	if value.Kind() != reflect.Ptr || value.IsNil() || value.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("gonfig: Marshal target must be a non nil pointer to a struct, got %T", target)
+	}
+	d := &decoder{config: config}
+	d.decodeStruct(value.Elem(), "", "")
+	return d.errs.err()
+}
+
+// decodes the values of a Configurable into go values
+type decoder struct {
+	config Configurable
+	// sorted keys of config, loaded when first needed
+	keys []string
+	// errors of the fields that failed to decode or validate
+	errs MarshalErrors
+}
+
+// returns the trimmed value of key, ok is false if the key is not set or empty
+func (self *decoder) lookup(key string) (string, bool) {
+	value, _ := LookupConfig(self.config, key)
+	value = strings.TrimSpace(value)
+	return value, value != ""
+}
+
+// returns the keys of the config that start with prefix
+func (self *decoder) prefixed(prefix string) []string {
+	if self.keys == nil {
+		all := self.config.All()
+		self.keys = make([]string, 0, len(all))
+		for key := range all {
+			self.keys = append(self.keys, key)
+		}
+		sort.Strings(self.keys)
+	}
+	keys := []string{}
+	for i := sort.SearchStrings(self.keys, prefix); i < len(self.keys) && strings.HasPrefix(self.keys[i], prefix); i++ {
+		keys = append(keys, self.keys[i])
+	}
+	return keys
+}
+
+// reports whether key or any key nested under it is set
+func (self *decoder) exists(key string) bool {
+	if _, ok := self.lookup(key); ok {
+		return true
+	}
+	return len(self.prefixed(key+":")) > 0
+}
+
+// reports whether key is set for a field of type typ, maps, structs and slices of structs are set by
+// the keys nested under key while other values are set only by key itself
+func (self *decoder) isSet(key string, typ reflect.Type) bool {
+	typ = indirectType(typ)
+	switch {
+	case isText(typ):
+	case typ.Kind() == reflect.Struct, typ.Kind() == reflect.Map, typ.Kind() == reflect.Slice && isNested(typ.Elem()):
+		return self.exists(key)
+	}
+	_, ok := self.lookup(key)
+	return ok
+}
+
+// decodes the fields of a struct, errors of the fields are collected to self.errs
+func (self *decoder) decodeStruct(value reflect.Value, prefix, path string) {
+	typ := value.Type()
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if field.PkgPath != "" && !field.Anonymous {
+			continue
+		}
+		name := fieldKey(field)
+		if name == "-" {
+			continue
+		}
+		fieldValue := value.Field(i)
+		if name == "" {
+			// embedded structs share the prefix of the struct they are embedded in
+			if !field.Anonymous || indirectType(field.Type).Kind() != reflect.Struct || isText(field.Type) {
+				continue
+			}
+			if fieldValue.Kind() == reflect.Ptr {
+				if fieldValue.IsNil() {
+					if !fieldValue.CanSet() {
+						continue
+					}
+					fieldValue.Set(reflect.New(field.Type.Elem()))
+				}
+				fieldValue = fieldValue.Elem()
+			}
+			self.decodeStruct(fieldValue, prefix, joinField(path, field.Name))
+			continue
+		}
+		if field.PkgPath != "" {
+			continue
+		}
+		self.decodeField(fieldValue, field, prefix+name, joinField(path, field.Name))
+	}
+}
+
+// decodes a tagged field, applies its default if key is not set and validates the decoded value
+func (self *decoder) decodeField(value reflect.Value, field reflect.StructField, key, path string) {
+	required, _ := strconv.ParseBool(field.Tag.Get("required"))
+	if field.Type.Kind() == reflect.Struct && !isText(field.Type) {
+		// nested structs are always decoded so the defaults of their fields are applied
+		if required && !self.exists(key) {
+			self.errs = append(self.errs, &KeyError{Key: key, Field: path, Err: ErrRequired})
+		}
+		self.decodeStruct(value, key+":", path)
+		return
+	}
+	raw, _ := self.lookup(key)
+	if self.isSet(key, field.Type) {
+		if err := self.decode(value, key, path); err != nil {
+			self.errs = append(self.errs, err.(*KeyError))
+			return
+		}
+	} else if def, ok := field.Tag.Lookup("default"); ok {
+		raw = def
+		if err := decodeValue(value, def); err != nil {
+			self.errs = append(self.errs, &KeyError{Key: key, Field: path, Value: def, Err: fmt.Errorf("invalid default: %s", err)})
+			return
+		}
+	} else {
+		if required {
+			self.errs = append(self.errs, &KeyError{Key: key, Field: path, Err: ErrRequired})
+		}
+		return
+	}
+	if err := validate(value, field.Tag); err != nil {
+		self.errs = append(self.errs, &KeyError{Key: key, Field: path, Value: raw, Err: err})
+	}
+}
+
+// decodes the value of key or the keys nested under it into value
+func (self *decoder) decode(value reflect.Value, key, path string) error {
+	typ := value.Type()
+	if typ.Kind() == reflect.Ptr {
+		if !self.exists(key) {
+			return nil
+		}
+		if value.IsNil() {
+			value.Set(reflect.New(typ.Elem()))
+		}
+		return self.decode(value.Elem(), key, path)
+	}
+	switch {
+	case isText(typ):
+	case typ.Kind() == reflect.Struct:
+		self.decodeStruct(value, key+":", path)
+		return nil
+	case typ.Kind() == reflect.Map:
+		return self.decodeMap(value, key, path)
+	case typ.Kind() == reflect.Slice && isNested(typ.Elem()):
+		return self.decodeIndexed(value, key, path)
+	}
+	raw, ok := self.lookup(key)
+	if !ok {
+		return nil
+	}
+	if err := decodeValue(value, raw); err != nil {
+		return &KeyError{Key: key, Field: path, Value: raw, Err: err}
+	}
+	return nil
+}
+
+// decodes a map from the name=value pairs of key or the keys nested under key
+func (self *decoder) decodeMap(value reflect.Value, key, path string) error {
+	typ := value.Type()
+	if typ.Key().Kind() != reflect.String {
+		return &KeyError{Key: key, Field: path, Err: fmt.Errorf("unsupported map key type %s", typ.Key())}
+	}
+	names := []string{}
+	if raw, ok := self.lookup(key); ok && !isNested(typ.Elem()) {
+		if err := decodeValue(value, raw); err != nil {
+			return &KeyError{Key: key, Field: path, Value: raw, Err: err}
+		}
+		return nil
+	}
+	for _, nested := range self.prefixed(key + ":") {
+		name := strings.TrimPrefix(nested, key+":")
+		if isNested(typ.Elem()) {
+			// structs decode all the keys under the first segment
+			name = strings.SplitN(name, ":", 2)[0]
+		}
+		if len(names) == 0 || names[len(names)-1] != name {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return nil
+	}
+	if value.IsNil() {
+		value.Set(reflect.MakeMap(typ))
+	}
+	for _, name := range names {
+		elem := reflect.New(typ.Elem()).Elem()
+		if existing := value.MapIndex(reflect.ValueOf(name).Convert(typ.Key())); existing.IsValid() {
+			elem.Set(existing)
+		}
+		if err := self.decode(elem, key+":"+name, path+"["+name+"]"); err != nil {
+			return err
+		}
+		value.SetMapIndex(reflect.ValueOf(name).Convert(typ.Key()), elem)
+	}
+	return nil
+}
+
+// decodes a slice of structs from the keys nested under the indexes of key
+func (self *decoder) decodeIndexed(value reflect.Value, key, path string) error {
+	typ := value.Type()
+	slice := reflect.MakeSlice(typ, 0, 0)
+	for i := 0; self.exists(key + ":" + strconv.Itoa(i)); i++ {
+		elem := reflect.New(typ.Elem()).Elem()
+		if i < value.Len() {
+			elem.Set(value.Index(i))
+		}
+		if err := self.decode(elem, key+":"+strconv.Itoa(i), path+"["+strconv.Itoa(i)+"]"); err != nil {
+			return err
+		}
+		slice = reflect.Append(slice, elem)
+	}
+	if slice.Len() > 0 {
+		value.Set(slice)
+	}
+	return nil
+}
+
+// parses raw into value
+func decodeValue(value reflect.Value, raw string) error {
+	typ := value.Type()
+	if typ.Kind() == reflect.Ptr {
+		elem := reflect.New(typ.Elem())
+		if err := decodeValue(elem.Elem(), raw); err != nil {
+			return err
+		}
+		value.Set(elem)
+		return nil
+	}
+	if reflect.PtrTo(typ).Implements(textUnmarshalerType) {
+		return value.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(raw))
+	}
+	if typ == durationType {
+		duration, err := time.ParseDuration(raw)
+		if err != nil {
+			return err
+		}
+		value.SetInt(int64(duration))
+		return nil
+	}
+	switch typ.Kind() {
+	case reflect.Bool:
+		value.SetBool(parseBool(raw))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(raw, 10, typ.Bits())
+		if err != nil {
+			return unwrapNumError(err)
+		}
+		value.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		u, err := strconv.ParseUint(raw, 10, typ.Bits())
+		if err != nil {
+			return unwrapNumError(err)
+		}
+		value.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(raw, typ.Bits())
+		if err != nil {
+			return unwrapNumError(err)
+		}
+		value.SetFloat(f)
+	case reflect.Complex64, reflect.Complex128:
+		c, err := strconv.ParseComplex(raw, typ.Bits())
+		if err != nil {
+			return unwrapNumError(err)
+		}
+		value.SetComplex(c)
+	case reflect.String:
+		value.SetString(raw)
+	case reflect.Map:
+		if typ.Key().Kind() != reflect.String {
+			return errors.New("unsupported map key type " + typ.Key().String())
+		}
+		pairs, err := parseMap(raw)
+		if err != nil {
+			return err
+		}
+		if value.IsNil() {
+			value.Set(reflect.MakeMap(typ))
+		}
+		for name, v := range pairs {
+			elem := reflect.New(typ.Elem()).Elem()
+			if err := decodeValue(elem, v); err != nil {
+				return err
+			}
+			value.SetMapIndex(reflect.ValueOf(name).Convert(typ.Key()), elem)
+		}
+	case reflect.Slice:
+		parts := trimsplit(raw, ",")
+		slice := reflect.MakeSlice(typ, len(parts), len(parts))
+		for i, part := range parts {
+			if err := decodeValue(slice.Index(i), part); err != nil {
+				return err
+			}
+		}
+		value.Set(slice)
+	default:
+		return errors.New("unsupported type " + typ.String())
+	}
+	return nil
+}
+
+// returns the key in the gonfig tag of field, options after a comma are not part of the key
+func fieldKey(field reflect.StructField) string {
+	return strings.TrimSpace(strings.SplitN(field.Tag.Get("gonfig"), ",", 2)[0])
+}
+
+// joins the name of a field to the path of its struct
+func joinField(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+func indirectType(typ reflect.Type) reflect.Type {
+	if typ.Kind() == reflect.Ptr {
+		return typ.Elem()
+	}
+	return typ
+}
+
+// reports whether values of typ are decoded from a single value with encoding.TextUnmarshaler
+func isText(typ reflect.Type) bool {
+	return typ.Implements(textUnmarshalerType) || reflect.PtrTo(typ).Implements(textUnmarshalerType)
+}
+
+// reports whether values of typ are decoded from the keys nested under their key
+func isNested(typ reflect.Type) bool {
+	typ = indirectType(typ)
+	return !isText(typ) && (typ.Kind() == reflect.Struct || typ.Kind() == reflect.Map)
+}
@@ -0,0 +1,146 @@
+package gonfig_test
+
+import (
+	"errors"
+	"net"
+	"strconv"
+	"time"
+
+	. "github.com/Nomon/gonfig"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type marshalServer struct {
+	Name string `gonfig:"name"`
+	Port uint16 `gonfig:"port"`
+}
+
+type marshalLimits struct {
+	Burst int `gonfig:"burst"`
+}
+
+type marshalConfig struct {
+	marshalLimits
+	Name     string            `gonfig:"name"`
+	Debug    bool              `gonfig:"debug"`
+	Workers  int8              `gonfig:"workers"`
+	Size     uint64            `gonfig:"size"`
+	Ratio    float32           `gonfig:"ratio"`
+	Timeout  time.Duration     `gonfig:"timeout"`
+	Started  time.Time         `gonfig:"started"`
+	IP       net.IP            `gonfig:"ip"`
+	Tags     []string          `gonfig:"tags"`
+	Ports    []int             `gonfig:"ports"`
+	Labels   map[string]string `gonfig:"labels"`
+	Weights  map[string]int    `gonfig:"weights"`
+	Optional *int              `gonfig:"optional"`
+	Missing  *int              `gonfig:"missing"`
+	Database struct {
+		Host string `gonfig:"host"`
+		Port int    `gonfig:"port"`
+	} `gonfig:"db"`
+	Cache    *marshalServer           `gonfig:"cache"`
+	Servers  []marshalServer          `gonfig:"servers"`
+	Named    map[string]marshalServer `gonfig:"named"`
+	Skipped  string                   `gonfig:"-"`
+	Untagged string
+}
+
+var _ = Describe("Marshal", func() {
+	var cfg *Gonfig
+	BeforeEach(func() {
+		cfg = NewConfig(nil)
+		cfg.Use("memory", NewMemoryConfig()).Reset(map[string]string{
+			"burst":              "10",
+			"name":               "app",
+			"debug":              "1",
+			"workers":            "4",
+			"size":               "1024",
+			"ratio":              "0.5",
+			"timeout":            "2s",
+			"started":            "2020-01-02T03:04:05Z",
+			"ip":                 "127.0.0.1",
+			"tags":               "a, b",
+			"ports":              "80,443",
+			"labels":             "env=prod,team=core",
+			"weights:a":          "1",
+			"weights:b":          "2",
+			"optional":           "5",
+			"db:host":            "localhost",
+			"db:port":            "5432",
+			"cache:name":         "redis",
+			"servers:0:name":     "a",
+			"servers:0:port":     "8080",
+			"servers:1:name":     "b",
+			"named:primary:name": "p",
+			"-":                  "skipped",
+			"Untagged":           "untagged",
+		})
+	})
+	It("Should decode all supported types", func() {
+		var target marshalConfig
+		Expect(cfg.Marshal(&target)).To(Succeed())
+		Expect(target.Burst).To(Equal(10))
+		Expect(target.Name).To(Equal("app"))
+		Expect(target.Debug).To(BeTrue())
+		Expect(target.Workers).To(Equal(int8(4)))
+		Expect(target.Size).To(Equal(uint64(1024)))
+		Expect(target.Ratio).To(Equal(float32(0.5)))
+		Expect(target.Timeout).To(Equal(2 * time.Second))
+		Expect(target.Started).To(Equal(time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)))
+		Expect(target.IP.String()).To(Equal("127.0.0.1"))
+		Expect(target.Tags).To(Equal([]string{"a", "b"}))
+		Expect(target.Ports).To(Equal([]int{80, 443}))
+		Expect(target.Labels).To(Equal(map[string]string{"env": "prod", "team": "core"}))
+		Expect(target.Weights).To(Equal(map[string]int{"a": 1, "b": 2}))
+		Expect(*target.Optional).To(Equal(5))
+		Expect(target.Missing).To(BeNil())
+		Expect(target.Skipped).To(BeEmpty())
+		Expect(target.Untagged).To(BeEmpty())
+	})
+	It("Should decode nested structs from prefixed keys", func() {
+		var target marshalConfig
+		Expect(cfg.Marshal(&target)).To(Succeed())
+		Expect(target.Database.Host).To(Equal("localhost"))
+		Expect(target.Database.Port).To(Equal(5432))
+		Expect(target.Cache).To(Equal(&marshalServer{Name: "redis"}))
+		Expect(target.Servers).To(Equal([]marshalServer{{"a", 8080}, {"b", 0}}))
+		Expect(target.Named).To(Equal(map[string]marshalServer{"primary": {Name: "p"}}))
+	})
+	It("Should keep the values of fields whose keys are not set", func() {
+		target := marshalConfig{Skipped: "keep"}
+		target.Database.Host = "override"
+		cfg.Use("memory").Set("db:host", "")
+		Expect(cfg.Marshal(&target)).To(Succeed())
+		Expect(target.Database.Host).To(Equal("override"))
+		Expect(target.Skipped).To(Equal("keep"))
+	})
+	It("Should name the key and field in errors", func() {
+		cfg.Use("memory").Set("servers:1:port", "70000")
+		var target marshalConfig
+		err := cfg.Marshal(&target)
+		Expect(err).To(MatchError(`gonfig: marshal failed: key "servers:1:port" (field Servers[1].Port): invalid value "70000": value out of range`))
+		var keyErr *KeyError
+		Expect(errors.As(err, &keyErr)).To(BeTrue())
+		Expect(keyErr.Key).To(Equal("servers:1:port"))
+		Expect(keyErr.Field).To(Equal("Servers[1].Port"))
+		Expect(errors.Is(err, strconv.ErrRange)).To(BeTrue())
+
+		cfg.Use("memory").Set("servers:1:port", "")
+		cfg.Use("memory").Set("db:port", "abc")
+		Expect(cfg.Marshal(&target)).To(MatchError(ContainSubstring("field Database.Port")))
+	})
+	It("Should error for targets that are not pointers to structs", func() {
+		var target marshalConfig
+		Expect(cfg.Marshal(target)).To(HaveOccurred())
+		Expect(cfg.Marshal(nil)).To(HaveOccurred())
+	})
+	It("Should marshal any Configurable", func() {
+		memory := NewMemoryConfig()
+		memory.Set("name", "memory")
+		var target marshalServer
+		Expect(MarshalConfig(memory, &target)).To(Succeed())
+		Expect(target.Name).To(Equal("memory"))
+	})
+})
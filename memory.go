@@ -1,53 +1,88 @@
 package gonfig
 
+import (
+	"sync"
+	"sync/atomic"
+)
+
 // MemoryConfig is a simple abstraction to map[]interface{} for in process memory backed configuration
-// only implements Configurable use JsonConfig to save/load if needed
+// only implements Configurable use JsonConfig to save/load if needed.
+// MemoryConfig is safe for concurrent use, reads are lock-free and see an immutable
+// snapshot of the data that writers replace atomically, so every Set copies the data.
 type MemoryConfig struct {
-	data map[string]string
+	// serializes writers
+	mu sync.Mutex
+	// immutable snapshot of the data, nil for an empty config
+	data atomic.Pointer[map[string]string]
+	// called after every change
+	observers observers
 }
 
 // Returns a new memory backed Configurable
 // The most basic Configurable simply backed by a map[string]interface{}
 func NewMemoryConfig() *MemoryConfig {
-	cfg := &MemoryConfig{make(map[string]string)}
-	cfg.init()
-	return cfg
+	return &MemoryConfig{}
 }
 
-func (self *MemoryConfig) init() {
-	self.data = make(map[string]string)
+// returns the current snapshot of the data, the returned map must not be modified
+func (self *MemoryConfig) snapshot() map[string]string {
+	if data := self.data.Load(); data != nil {
+		return *data
+	}
+	return nil
 }
 
 // if no arguments are proced Reset() re-creates the underlaying map
+// otherwise the data is replaced with a copy of the passed map.
 func (self *MemoryConfig) Reset(datas ...map[string]string) {
+	data := make(map[string]string)
 	if len(datas) >= 1 {
-		self.data = datas[0]
-	} else {
-		self.data = make(map[string]string)
+		for key, value := range datas[0] {
+			data[key] = value
+		}
 	}
-	return
+	self.mu.Lock()
+	self.data.Store(&data)
+	self.mu.Unlock()
+	self.observers.notify()
 }
 
 // Get key from map
 func (self *MemoryConfig) Get(key string) string {
-	if self.data == nil {
-		self.init()
-	}
-	return self.data[key]
+	return self.snapshot()[key]
 }
 
-// get all keys
+// Lookup key from map, reports whether the key is set
+func (self *MemoryConfig) Lookup(key string) (string, bool) {
+	value, ok := self.snapshot()[key]
+	return value, ok
+}
+
+// get a copy of all keys
 func (self *MemoryConfig) All() map[string]string {
-	if self.data == nil {
-		self.init()
+	snapshot := self.snapshot()
+	data := make(map[string]string, len(snapshot))
+	for key, value := range snapshot {
+		data[key] = value
 	}
-	return self.data
+	return data
 }
 
 // Set a key to value
 func (self *MemoryConfig) Set(key, value string) {
-	if self.data == nil {
-		self.init()
+	self.mu.Lock()
+	snapshot := self.snapshot()
+	data := make(map[string]string, len(snapshot)+1)
+	for k, v := range snapshot {
+		data[k] = v
 	}
-	self.data[key] = value
+	data[key] = value
+	self.data.Store(&data)
+	self.mu.Unlock()
+	self.observers.notify()
+}
+
+// Calls fn after every Set and Reset, the returned func stops observing
+func (self *MemoryConfig) Observe(fn func()) func() {
+	return self.observers.add(fn)
 }
@@ -31,4 +31,13 @@ var _ = Describe("MemoryConfig", func() {
 		cfg2.Reset(cfg.All())
 		Expect(cfg.Get("test")).To(Equal(cfg2.Get("test")))
 	})
+	It("Should tell unset keys from empty values with Lookup", func() {
+		mem := NewMemoryConfig()
+		mem.Set("empty", "")
+		value, ok := mem.Lookup("empty")
+		Expect(ok).To(BeTrue())
+		Expect(value).To(Equal(""))
+		_, ok = mem.Lookup("unset")
+		Expect(ok).To(BeFalse())
+	})
 })
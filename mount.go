@@ -0,0 +1,227 @@
+package gonfig
+
+import (
+	"sync/atomic"
+)
+
+// A named Configurable mounted in a Gonfig hierarchy.
+// Mounts are immutable once mounted, changing a mount replaces it in the hierarchy.
+type Mount struct {
+	// Name the config was mounted with, used by Gonfig.Use(name) lookups
+	Name string
+	// Mounts with a higher Priority are searched first, mounts with equal
+	// Priority are searched in the order they were mounted.
+	Priority int
+	// Optional mounts do not fail Gonfig.Load when their source does not exist
+	Optional bool
+	Configurable
+	// the error returned by the last Load of the mount
+	err atomic.Pointer[error]
+	// stops the Gonfig from observing the mounted config
+	stop func()
+}
+
+// Returns the error returned by the last Load of the mount
+func (self *Mount) Err() error {
+	if err := self.err.Load(); err != nil {
+		return *err
+	}
+	return nil
+}
+
+// loads the mounted config and records the error
+func (self *Mount) load() error {
+	err := LoadConfig(self.Configurable)
+	self.err.Store(&err)
+	return err
+}
+
+// returns a copy of the mount with the configurable replaced by config
+func (self *Mount) with(config Configurable) *Mount {
+	mount := &Mount{Name: self.Name, Priority: self.Priority, Optional: self.Optional, Configurable: config, stop: self.stop}
+	mount.err.Store(self.err.Load())
+	return mount
+}
+
+// stops observing the mounted config once it is unmounted
+func (self *Mount) unmounted() {
+	if self != nil && self.stop != nil {
+		self.stop()
+	}
+}
+
+// returns a new loaded mount of config that the Gonfig observes for changes
+func (self *Gonfig) newMount(name string, priority int, config Configurable) *Mount {
+	mount := &Mount{Name: name, Priority: priority, Configurable: config}
+	mount.load()
+	mount.stop = ObserveConfig(config, self.changed)
+	return mount
+}
+
+// Use config as named config and return an already set and loaded config
+// mounts a new configuration in the hierarchy.
+// conf.Use("global", NewUrlConfig("http://host.com/config..json")).
+// conf.Use("local", NewFileConfig("./config.json"))
+// err := conf.Load();.
+// Then get variable from specific config.
+// conf.Use("global").Get("key").
+// or traverse the hierarchy and search for "key".
+// conf.Get("key").
+// conf.Use("name") returns a nil value for non existing config named "name".
+// Configs are searched in the order they were mounted, so "global" above is searched before "local".
+// Using an already mounted name replaces the config but keeps its position in the hierarchy.
+func (self *Gonfig) Use(name string, config ...Configurable) Configurable {
+	if len(config) == 0 {
+		mounts := self.snapshot()
+		if i := mountIndex(mounts, name); i >= 0 {
+			return mounts[i].Configurable
+		}
+		return nil
+	}
+	mount := self.newMount(name, 0, config[0])
+	self.update(func(mounts []*Mount) ([]*Mount, *Mount) {
+		if i := mountIndex(mounts, name); i >= 0 {
+			replaced := mounts[i]
+			mount.Priority = replaced.Priority
+			mount.Optional = replaced.Optional
+			mounts[i] = mount
+			return mounts, replaced
+		}
+		return insertMount(mounts, priorityIndex(mounts, 0), mount), nil
+	})
+	return config[0]
+}
+
+// Mounts config as name like Use but marks the mount optional, so a missing
+// source such as a non existing json file does not fail Load.
+func (self *Gonfig) UseOptional(name string, config Configurable) Configurable {
+	self.Use(name, config)
+	self.SetOptional(name, true)
+	return config
+}
+
+// Marks the mount named name as optional or required, mounts are required by default.
+func (self *Gonfig) SetOptional(name string, optional bool) {
+	self.update(func(mounts []*Mount) ([]*Mount, *Mount) {
+		if i := mountIndex(mounts, name); i >= 0 {
+			mounts[i] = mounts[i].with(mounts[i].Configurable)
+			mounts[i].Optional = optional
+		}
+		return mounts, nil
+	})
+}
+
+// Mounts config as name with an explicit priority, configs with a higher priority are
+// searched before configs with a lower one regardless of the order they were mounted in.
+// Configs mounted with Use have a priority of 0.
+// conf.Use("local", NewJsonConfig("./config.json"))
+// conf.UsePriority("argv", 10, NewArgvConfig("myapp."))
+// searches "argv" before "local".
+func (self *Gonfig) UsePriority(name string, priority int, config Configurable) Configurable {
+	mount := self.newMount(name, priority, config)
+	self.update(func(mounts []*Mount) ([]*Mount, *Mount) {
+		mounts, replaced := unmount(mounts, name)
+		return insertMount(mounts, priorityIndex(mounts, priority), mount), replaced
+	})
+	return config
+}
+
+// Mounts config as name so that it is searched right before the config mounted as before.
+// If before is not mounted config is mounted as with Use.
+func (self *Gonfig) UseBefore(name, before string, config Configurable) Configurable {
+	return self.useRelative(name, before, 0, config)
+}
+
+// Mounts config as name so that it is searched right after the config mounted as after.
+// If after is not mounted config is mounted as with Use.
+func (self *Gonfig) UseAfter(name, after string, config Configurable) Configurable {
+	return self.useRelative(name, after, 1, config)
+}
+
+// Returns the names of the mounted configs in the order they are searched
+func (self *Gonfig) Mounts() []string {
+	mounts := self.snapshot()
+	names := make([]string, len(mounts))
+	for i, mount := range mounts {
+		names[i] = mount.Name
+	}
+	return names
+}
+
+// Returns the mounted configs in the order they are searched
+func (self *Gonfig) Configs() []*Mount {
+	return append([]*Mount{}, self.snapshot()...)
+}
+
+// mounts config next to the mount named relative, offset 0 places it before and 1 after it.
+// The new mount inherits the priority of relative so the ordering is kept by later UsePriority calls.
+func (self *Gonfig) useRelative(name, relative string, offset int, config Configurable) Configurable {
+	if name == relative {
+		return self.Use(name, config)
+	}
+	mount := self.newMount(name, 0, config)
+	self.update(func(mounts []*Mount) ([]*Mount, *Mount) {
+		mounts, replaced := unmount(mounts, name)
+		i := mountIndex(mounts, relative)
+		if i < 0 {
+			return insertMount(mounts, priorityIndex(mounts, 0), mount), replaced
+		}
+		mount.Priority = mounts[i].Priority
+		return insertMount(mounts, i+offset, mount), replaced
+	})
+	return config
+}
+
+// returns the current snapshot of the mounts in search order, the returned slice must not be modified
+func (self *Gonfig) snapshot() []*Mount {
+	if mounts := self.mounts.Load(); mounts != nil {
+		return *mounts
+	}
+	return nil
+}
+
+// replaces the mounts with the result of calling fn with a copy of the current mounts,
+// fn returns the new mounts and the mount it replaced if any.
+func (self *Gonfig) update(fn func([]*Mount) ([]*Mount, *Mount)) {
+	self.mu.Lock()
+	mounts, replaced := fn(append([]*Mount{}, self.snapshot()...))
+	self.mounts.Store(&mounts)
+	self.mu.Unlock()
+	replaced.unmounted()
+	self.changed()
+}
+
+// returns the position of the mount named name in mounts or -1 if it is not mounted.
+func mountIndex(mounts []*Mount, name string) int {
+	for i, mount := range mounts {
+		if mount.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// returns the position after the last mount with the same or higher priority
+func priorityIndex(mounts []*Mount, priority int) int {
+	i := 0
+	for i < len(mounts) && mounts[i].Priority >= priority {
+		i++
+	}
+	return i
+}
+
+func insertMount(mounts []*Mount, i int, mount *Mount) []*Mount {
+	mounts = append(mounts, nil)
+	copy(mounts[i+1:], mounts[i:])
+	mounts[i] = mount
+	return mounts
+}
+
+// removes the mount named name, returns the remaining mounts and the removed mount
+func unmount(mounts []*Mount, name string) ([]*Mount, *Mount) {
+	if i := mountIndex(mounts, name); i >= 0 {
+		removed := mounts[i]
+		return append(mounts[:i], mounts[i+1:]...), removed
+	}
+	return mounts, nil
+}
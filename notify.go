@@ -0,0 +1,272 @@
+package gonfig
+
+import (
+	"sort"
+	"strings"
+	"sync"
+)
+
+// A Configurable that notifies observers when its data changes
+type ObservableConfigurable interface {
+	Configurable
+	// Calls fn after every change to the data, the returned func stops observing
+	Observe(fn func()) func()
+}
+
+// Calls fn whenever config changes if config is an ObservableConfigurable,
+// returns a func that stops observing the config.
+func ObserveConfig(config Configurable, fn func()) func() {
+	if observable, ok := config.(ObservableConfigurable); ok {
+		return observable.Observe(fn)
+	}
+	return func() {}
+}
+
+// The kind of a Change
+type ChangeType int
+
+const (
+	// The key was not set before the change
+	KeyAdded ChangeType = iota
+	// The key is no longer set after the change
+	KeyRemoved
+	// The value of the key changed
+	KeyChanged
+)
+
+func (self ChangeType) String() string {
+	switch self {
+	case KeyAdded:
+		return "added"
+	case KeyRemoved:
+		return "removed"
+	case KeyChanged:
+		return "changed"
+	}
+	return "unknown"
+}
+
+// A change to the value a key resolves to
+type Change struct {
+	Key  string
+	Type ChangeType
+	// Old is empty for added keys and New for removed keys
+	Old, New string
+}
+
+// a set of observer funcs that can be added and removed concurrently
+type observers struct {
+	mu   sync.Mutex
+	next int
+	fns  map[int]func()
+}
+
+// adds fn to the observers, returns a func that removes it
+func (self *observers) add(fn func()) func() {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+	if self.fns == nil {
+		self.fns = make(map[int]func())
+	}
+	id := self.next
+	self.next++
+	self.fns[id] = fn
+	return func() {
+		self.mu.Lock()
+		delete(self.fns, id)
+		self.mu.Unlock()
+	}
+}
+
+// calls all observers, observers added or removed during the call may or may not be called
+func (self *observers) notify() {
+	self.mu.Lock()
+	fns := make([]func(), 0, len(self.fns))
+	for _, fn := range self.fns {
+		fns = append(fns, fn)
+	}
+	self.mu.Unlock()
+	for _, fn := range fns {
+		fn()
+	}
+}
+
+type subscriber struct {
+	fn       func([]Change)
+	prefixes []string
+}
+
+// returns the changes the subscriber is interested in
+func (self *subscriber) filter(changes []Change) []Change {
+	if len(self.prefixes) == 0 {
+		return changes
+	}
+	var filtered []Change
+	for _, change := range changes {
+		for _, prefix := range self.prefixes {
+			if strings.HasPrefix(change.Key, prefix) {
+				filtered = append(filtered, change)
+				break
+			}
+		}
+	}
+	return filtered
+}
+
+// the subscribers of a Gonfig and the resolved values they were last notified of
+type notifier struct {
+	mu          sync.Mutex
+	next        int
+	subscribers map[int]*subscriber
+	// the values All resolved to after the last change, nil without subscribers
+	last map[string]string
+	// stops observing the overrides and defaults, nil until they are observed
+	stopLayers []func()
+	// changes waiting to be delivered in the order they were computed
+	queue []notification
+	// whether a call to changed is delivering the queue
+	delivering bool
+}
+
+// changes and the subscribers they are delivered to
+type notification struct {
+	changes     []Change
+	subscribers []*subscriber
+}
+
+// Subscribes fn to changes of the values keys resolve to in the hierarchy, fn is called with the
+// changes sorted by key whenever a Set, Reset, Load or Use on the Gonfig or any of its mounts changes
+// what Get returns. If prefixes are given only changes to keys with one of the prefixes are delivered.
+// The returned func unsubscribes fn.
+// Changes are delivered in the order they happen, changes made while subscribers are being called,
+// by other goroutines or by the subscribers themselves, are delivered after they return.
+// Changes are detected through ObservableConfigurable, so replacing the Gonfig.Configurable or
+// Gonfig.Defaults fields after the first Subscribe is not noticed.
+// conf.Subscribe(func(changes []Change) { resizePool(conf.Get("db:pool")) }, "db:")
+func (self *Gonfig) Subscribe(fn func([]Change), prefixes ...string) func() {
+	self.observeLayers()
+	n := &self.notifier
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.subscribers == nil {
+		n.subscribers = make(map[int]*subscriber)
+	}
+	if len(n.subscribers) == 0 {
+		n.last = self.All()
+	}
+	id := n.next
+	n.next++
+	n.subscribers[id] = &subscriber{fn, prefixes}
+	return func() {
+		n.mu.Lock()
+		defer n.mu.Unlock()
+		delete(n.subscribers, id)
+		if len(n.subscribers) == 0 {
+			n.last = nil
+		}
+	}
+}
+
+// Calls fn after every change in the hierarchy, lets a Gonfig observe Gonfigs mounted in it
+func (self *Gonfig) Observe(fn func()) func() {
+	self.observeLayers()
+	return self.observers.add(fn)
+}
+
+// starts observing the overrides and defaults once
+func (self *Gonfig) observeLayers() {
+	n := &self.notifier
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.stopLayers == nil {
+		n.stopLayers = []func(){
+			ObserveConfig(self.Configurable, self.changed),
+			ObserveConfig(self.Defaults, self.changed),
+		}
+	}
+}
+
+// called after a change anywhere in the hierarchy, notifies observers and subscribers.
+// Changes are queued in the order they are computed and delivered by one caller at a time,
+// so concurrent changes, or changes made by subscribers, reach subscribers in order.
+func (self *Gonfig) changed() {
+	self.observers.notify()
+	n := &self.notifier
+	n.mu.Lock()
+	if len(n.subscribers) == 0 {
+		n.mu.Unlock()
+		return
+	}
+	current := self.All()
+	changes := diff(n.last, current)
+	n.last = current
+	if len(changes) == 0 {
+		n.mu.Unlock()
+		return
+	}
+	subscribers := make([]*subscriber, 0, len(n.subscribers))
+	for _, subscriber := range n.subscribers {
+		subscribers = append(subscribers, subscriber)
+	}
+	n.queue = append(n.queue, notification{changes, subscribers})
+	if n.delivering {
+		// the caller delivering the queue delivers these changes after the earlier ones
+		n.mu.Unlock()
+		return
+	}
+	n.delivering = true
+	n.mu.Unlock()
+	self.deliver()
+}
+
+// delivers the queued changes until the queue is empty
+func (self *Gonfig) deliver() {
+	n := &self.notifier
+	done := false
+	defer func() {
+		if !done {
+			// a subscriber panicked, let the next change deliver the rest of the queue
+			n.mu.Lock()
+			n.delivering = false
+			n.mu.Unlock()
+		}
+	}()
+	for {
+		n.mu.Lock()
+		if len(n.queue) == 0 {
+			n.delivering = false
+			n.mu.Unlock()
+			done = true
+			return
+		}
+		next := n.queue[0]
+		n.queue = n.queue[1:]
+		n.mu.Unlock()
+		for _, subscriber := range next.subscribers {
+			if filtered := subscriber.filter(next.changes); len(filtered) > 0 {
+				subscriber.fn(filtered)
+			}
+		}
+	}
+}
+
+// returns the changes from old to new sorted by key
+func diff(old, new map[string]string) []Change {
+	var changes []Change
+	for key, value := range new {
+		if oldValue, ok := old[key]; !ok {
+			changes = append(changes, Change{key, KeyAdded, "", value})
+		} else if oldValue != value {
+			changes = append(changes, Change{key, KeyChanged, oldValue, value})
+		}
+	}
+	for key, value := range old {
+		if _, ok := new[key]; !ok {
+			changes = append(changes, Change{key, KeyRemoved, value, ""})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Key < changes[j].Key
+	})
+	return changes
+}
@@ -0,0 +1,141 @@
+package gonfig_test
+
+import (
+	. "github.com/Nomon/gonfig"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"io/ioutil"
+	"os"
+	"runtime"
+	"strconv"
+	"sync"
+)
+
+var _ = Describe("Subscribe", func() {
+	var (
+		cfg         *Gonfig
+		changes     [][]Change
+		unsubscribe func()
+	)
+	BeforeEach(func() {
+		cfg = NewConfig(nil)
+		cfg.Use("mem", NewMemoryConfig())
+		cfg.Use("mem").Set("a", "1")
+		cfg.Defaults.Set("b", "default")
+		changes = nil
+		unsubscribe = cfg.Subscribe(func(c []Change) {
+			changes = append(changes, c)
+		})
+	})
+
+	It("Should deliver changes of Set on mounts, overrides and defaults", func() {
+		cfg.Use("mem").Set("a", "2")
+		cfg.Set("c", "3")
+		cfg.Defaults.Set("b", "changed")
+		Expect(changes).To(Equal([][]Change{
+			{{"a", KeyChanged, "1", "2"}},
+			{{"c", KeyAdded, "", "3"}},
+			{{"b", KeyChanged, "default", "changed"}},
+		}))
+	})
+
+	It("Should deliver the removed keys on Reset", func() {
+		cfg.Use("mem").Reset()
+		Expect(changes).To(Equal([][]Change{{{"a", KeyRemoved, "1", ""}}}))
+	})
+
+	It("Should only deliver changes to the resolved values", func() {
+		cfg.Defaults.Set("a", "shadowed")
+		Expect(changes).To(BeEmpty())
+	})
+
+	It("Should deliver changes from mounting and nested hierarchies", func() {
+		nested := NewConfig(nil)
+		cfg.UseBefore("nested", "mem", nested)
+		nested.Use("deep", NewMemoryConfig()).Set("a", "nested")
+		Expect(changes).To(Equal([][]Change{{{"a", KeyChanged, "1", "nested"}}}))
+	})
+
+	It("Should deliver changes of reloads", func() {
+		path := "./config_notify.json"
+		defer os.Remove(path)
+		Expect(ioutil.WriteFile(path, []byte(`{"json":"1"}`), 0600)).To(Succeed())
+		cfg.Use("json", NewJsonConfig(path))
+		Expect(ioutil.WriteFile(path, []byte(`{"json":"2"}`), 0600)).To(Succeed())
+		Expect(cfg.Load()).To(Succeed())
+		Expect(changes).To(Equal([][]Change{
+			{{"json", KeyAdded, "", "1"}},
+			{{"json", KeyChanged, "1", "2"}},
+		}))
+	})
+
+	It("Should filter changes by key prefix", func() {
+		var filtered []Change
+		cfg.Subscribe(func(c []Change) {
+			filtered = append(filtered, c...)
+		}, "db:", "cache:")
+		cfg.Reset(map[string]string{"db:host": "localhost", "cache:size": "1", "other": "1"})
+		Expect(filtered).To(ConsistOf(
+			Change{"db:host", KeyAdded, "", "localhost"},
+			Change{"cache:size", KeyAdded, "", "1"},
+		))
+	})
+
+	It("Should stop delivering changes after unsubscribe", func() {
+		unsubscribe()
+		cfg.Set("c", "3")
+		Expect(changes).To(BeEmpty())
+	})
+
+	It("Should stop observing unmounted configs", func() {
+		mem := cfg.Use("mem")
+		cfg.Use("mem", NewMemoryConfig())
+		changes = nil
+		mem.Set("a", "old")
+		Expect(changes).To(BeEmpty())
+	})
+
+	It("Should deliver changes made by subscribers after the change being delivered", func() {
+		cfg.Subscribe(func(c []Change) {
+			if c[0].Key == "c" {
+				cfg.Set("d", "4")
+			}
+		})
+		cfg.Set("c", "3")
+		Expect(changes).To(Equal([][]Change{
+			{{"c", KeyAdded, "", "3"}},
+			{{"d", KeyAdded, "", "4"}},
+		}))
+	})
+
+	It("Should deliver concurrent changes in order", func() {
+		unsubscribe()
+		var mu sync.Mutex
+		applied := map[string]string{}
+		cfg.Subscribe(func(c []Change) {
+			// let other goroutines change the key meanwhile
+			runtime.Gosched()
+			mu.Lock()
+			defer mu.Unlock()
+			for _, change := range c {
+				Expect(applied[change.Key]).To(Equal(change.Old))
+				applied[change.Key] = change.New
+			}
+		}, "key")
+		var wg sync.WaitGroup
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer GinkgoRecover()
+				defer wg.Done()
+				for j := 0; j < 100; j++ {
+					cfg.Set("key", strconv.Itoa(i*100+j))
+				}
+			}(i)
+		}
+		wg.Wait()
+		mu.Lock()
+		defer mu.Unlock()
+		Expect(applied["key"]).To(Equal(cfg.Get("key")))
+	})
+})
@@ -0,0 +1,135 @@
+package gonfig
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// The interval a Poller reloads at if no interval is given
+var DefaultPollInterval = time.Minute
+
+// Poller reloads a ReadableConfig periodically, ie. a UrlConfig from a config server.
+// Configs with a LoadContext method, like UrlConfig, are loaded with a context that is
+// canceled when the Poller is stopped. Mounted configs notify the Gonfig subscribers
+// when a reload changes their values.
+type Poller struct {
+	// How often the config is reloaded, the options must be set before Start
+	Interval time.Duration
+	// A random delay of up to Jitter is added to each interval, so clients started together
+	// do not all poll at the same time
+	Jitter time.Duration
+	// The delay doubles from Interval after each consecutive failed reload up to MaxBackoff,
+	// it defaults to 16 times Interval
+	MaxBackoff time.Duration
+	// Called after each reload with the error returned by Load
+	OnReload func(error)
+
+	config ReadableConfig
+	// the error returned by the last reload
+	err     atomic.Pointer[error]
+	started atomic.Bool
+	once    sync.Once
+	ctx     context.Context
+	cancel  context.CancelFunc
+	done    chan struct{}
+}
+
+// Returns a new Poller that reloads config every interval, the poller does not
+// reload the config until Start is called.
+func NewPoller(config ReadableConfig, interval time.Duration) *Poller {
+	if interval <= 0 {
+		interval = DefaultPollInterval
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Poller{
+		Interval:   interval,
+		MaxBackoff: 16 * interval,
+		config:     config,
+		ctx:        ctx,
+		cancel:     cancel,
+		done:       make(chan struct{}),
+	}
+}
+
+// Starts polling in a background goroutine, calling Start again does nothing
+func (self *Poller) Start() {
+	if self.started.Swap(true) {
+		return
+	}
+	go func() {
+		defer close(self.done)
+		failures := 0
+		for {
+			timer := time.NewTimer(self.delay(failures))
+			select {
+			case <-self.ctx.Done():
+				timer.Stop()
+				return
+			case <-timer.C:
+			}
+			if err := self.reload(); err != nil {
+				failures++
+			} else {
+				failures = 0
+			}
+		}
+	}()
+}
+
+// Stops polling, cancels a reload in progress and waits for it to finish
+func (self *Poller) Stop() {
+	self.once.Do(self.cancel)
+	if self.started.Load() {
+		<-self.done
+	}
+}
+
+// Returns the error of the last reload, nil if it succeeded or no reload has happened
+func (self *Poller) Err() error {
+	if err := self.err.Load(); err != nil {
+		return *err
+	}
+	return nil
+}
+
+// returns the delay before the next reload after failures consecutive failed reloads
+func (self *Poller) delay(failures int) time.Duration {
+	delay := self.Interval
+	max := self.MaxBackoff
+	if max < self.Interval {
+		max = self.Interval
+	}
+	for i := 0; i < failures && delay < max; i++ {
+		delay *= 2
+	}
+	if delay > max {
+		delay = max
+	}
+	if self.Jitter > 0 {
+		delay += time.Duration(rand.Int63n(int64(self.Jitter)))
+	}
+	return delay
+}
+
+func (self *Poller) reload() error {
+	var err error
+	if loader, ok := self.config.(interface {
+		LoadContext(context.Context) error
+	}); ok {
+		err = loader.LoadContext(self.ctx)
+	} else {
+		err = self.config.Load()
+	}
+	if self.ctx.Err() != nil {
+		// stopped while reloading
+		return err
+	}
+	self.err.Store(&err)
+	if self.OnReload != nil {
+		self.OnReload(err)
+	}
+	return err
+}
@@ -0,0 +1,130 @@
+package gonfig_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+
+	. "github.com/Nomon/gonfig"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Poller", func() {
+	const interval = 5 * time.Millisecond
+	var (
+		server   *httptest.Server
+		mu       sync.Mutex
+		document string
+		version  int
+		status   int
+		requests int
+		full     int
+		poller   *Poller
+	)
+	BeforeEach(func() {
+		document, version, status, requests, full = `{"key":"a"}`, 1, http.StatusOK, 0, 0
+		poller = nil
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			defer mu.Unlock()
+			requests++
+			if status != http.StatusOK {
+				w.WriteHeader(status)
+				return
+			}
+			etag := fmt.Sprintf(`"%d"`, version)
+			if r.Header.Get("If-None-Match") == etag {
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+			full++
+			w.Header().Set("ETag", etag)
+			fmt.Fprint(w, document)
+		}))
+	})
+	AfterEach(func() {
+		if poller != nil {
+			poller.Stop()
+		}
+		server.Close()
+	})
+	update := func(fn func()) {
+		mu.Lock()
+		defer mu.Unlock()
+		fn()
+	}
+	counts := func() (int, int) {
+		mu.Lock()
+		defer mu.Unlock()
+		return requests, full
+	}
+
+	It("Should not download documents that are not modified", func() {
+		url := NewUrlConfig(server.URL)
+		Expect(url.Load()).To(Succeed())
+		Expect(url.Load()).To(Succeed())
+		requests, full := counts()
+		Expect(requests).To(Equal(2))
+		Expect(full).To(Equal(1))
+		Expect(url.Get("key")).To(Equal("a"))
+	})
+
+	It("Should apply the last document again when it is not modified", func() {
+		url := NewUrlConfig(server.URL)
+		Expect(url.Load()).To(Succeed())
+		url.Reset()
+		Expect(url.Load()).To(Succeed())
+		Expect(url.Get("key")).To(Equal("a"))
+		url.Set("key", "local")
+		Expect(url.Load()).To(Succeed())
+		Expect(url.Get("key")).To(Equal("a"))
+		_, full := counts()
+		Expect(full).To(Equal(1))
+	})
+
+	It("Should reload changed documents and notify subscribers", func() {
+		cfg := NewConfig(nil)
+		url := NewUrlConfig(server.URL).(*UrlConfig)
+		cfg.Use("url", url)
+		changes := make(chan []Change, 10)
+		cfg.Subscribe(func(c []Change) { changes <- c })
+		poller = url.Poll(interval)
+		update(func() { document, version = `{"key":"b"}`, 2 })
+		Eventually(changes).Should(Receive(Equal([]Change{{Key: "key", Type: KeyChanged, Old: "a", New: "b"}})))
+		Consistently(changes, 5*interval).ShouldNot(Receive())
+		Eventually(func() int { requests, _ := counts(); return requests }).Should(BeNumerically(">", 3))
+		_, full := counts()
+		Expect(full).To(Equal(2))
+	})
+
+	It("Should back off after failed reloads", func() {
+		url := NewUrlConfig(server.URL)
+		Expect(url.Load()).To(Succeed())
+		update(func() { status = http.StatusInternalServerError })
+		reloads := make(chan error, 100)
+		poller = NewPoller(url, interval)
+		poller.MaxBackoff = 40 * interval
+		poller.OnReload = func(err error) { reloads <- err }
+		poller.Start()
+		var statusErr *StatusError
+		Eventually(reloads).Should(Receive(WithTransform(func(err error) bool { return errors.As(err, &statusErr) }, BeTrue())))
+		Expect(poller.Err()).To(HaveOccurred())
+		Expect(url.Get("key")).To(Equal("a"))
+		// the delays double to 10, 20, 40 ms, so only a few reloads happen in 100ms
+		time.Sleep(100 * time.Millisecond)
+		Expect(len(reloads)).To(BeNumerically("<", 6))
+		update(func() { status, document, version = http.StatusOK, `{"key":"b"}`, 2 })
+		Eventually(func() string { return url.Get("key") }, time.Second).Should(Equal("b"))
+		Expect(poller.Err()).ToNot(HaveOccurred())
+	})
+
+	It("Should default MaxBackoff to 16 intervals", func() {
+		poller = NewPoller(NewUrlConfig(server.URL), time.Hour)
+		Expect(poller.MaxBackoff).To(Equal(16 * time.Hour))
+		poller.Stop()
+	})
+})
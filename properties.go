@@ -0,0 +1,224 @@
+package gonfig
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf16"
+)
+
+// PropertiesConfig is a WritableConfig backed by a Java .properties file.
+// The file is parsed as described by java.util.Properties: # and ! start comments,
+// keys are separated from values by =, : or whitespace, a line ending in a backslash continues
+// on the next line and \t, \n, \r, \f, \uXXXX and backslash escaped characters are unescaped.
+// If Separator is set it is replaced by ":" in the loaded keys, so with a Separator of "."
+// db.host=localhost is found from "db:host", and ":" is replaced by Separator when saving.
+type PropertiesConfig struct {
+	FileConfig
+	Separator string
+}
+
+// Returns a new WritableConfig backed by a .properties file at path, separator is mapped to
+// the ":" key separator, pass "" to load the keys as they are.
+// The file does not need to exist, if it does not exist the first Save call will create it.
+// Errors loading the file are not returned, use OpenPropertiesConfig or the error from Load
+// or Gonfig.Use to detect a missing or invalid file.
+func NewPropertiesConfig(path, separator string, cfg ...Configurable) WritableConfig {
+	conf, _ := OpenPropertiesConfig(path, separator, cfg...)
+	return conf
+}
+
+// Returns a new WritableConfig backed by a .properties file at path like NewPropertiesConfig,
+// along with the error from loading the file.
+func OpenPropertiesConfig(path, separator string, cfg ...Configurable) (WritableConfig, error) {
+	conf := &PropertiesConfig{FileConfig: openFile(path, cfg), Separator: separator}
+	return conf, LoadConfig(conf)
+}
+
+// Attempts to load the .properties file at PropertiesConfig.Path
+// and Set them into the underlaying Configurable
+func (self *PropertiesConfig) Load() error {
+	data, err := ioutil.ReadFile(self.Path)
+	if err != nil {
+		return err
+	}
+	out, err := unmarshalProperties(data)
+	if err != nil {
+		return err
+	}
+	if self.Separator != "" {
+		mapped := make(map[string]string, len(out))
+		for key, value := range out {
+			mapped[strings.Replace(key, self.Separator, ":", -1)] = value
+		}
+		out = mapped
+	}
+	self.Configurable.Reset(out)
+	return nil
+}
+
+// Attempts to save the configuration from the underlaying Configurable to the .properties file
+// at PropertiesConfig.Path, keys are written in sorted order.
+func (self *PropertiesConfig) Save() error {
+	data := self.Configurable.All()
+	keys := make([]string, 0, len(data))
+	for key := range data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var buffer bytes.Buffer
+	for _, key := range keys {
+		name := key
+		if self.Separator != "" {
+			name = strings.Replace(key, ":", self.Separator, -1)
+		}
+		buffer.WriteString(escapeProperty(name, true) + "=" + escapeProperty(data[key], false) + "\n")
+	}
+	return writeFile(self.Path, buffer.Bytes(), 0)
+}
+
+// Reloads the config whenever the .properties file changes, see Watcher
+func (self *PropertiesConfig) Watch(interval time.Duration) *Watcher {
+	return watchFile(self, self.Path, interval)
+}
+
+func unmarshalProperties(data []byte) (map[string]string, error) {
+	output := make(map[string]string)
+	lines := strings.Split(strings.Replace(strings.Replace(string(data), "\r\n", "\n", -1), "\r", "\n", -1), "\n")
+	for n := 0; n < len(lines); n++ {
+		line := strings.TrimLeft(lines[n], " \t\f")
+		if line == "" || line[0] == '#' || line[0] == '!' {
+			continue
+		}
+		// join continuation lines, a line continues if it ends in an odd number of backslashes
+		for continues(line) && n+1 < len(lines) {
+			n++
+			line = line[:len(line)-1] + strings.TrimLeft(lines[n], " \t\f")
+		}
+		if continues(line) {
+			line = line[:len(line)-1]
+		}
+		key, value := splitProperty(line)
+		var err error
+		if key, err = unescapeProperty(key); err != nil {
+			return nil, fmt.Errorf("properties: line %d: %s", n+1, err)
+		}
+		if value, err = unescapeProperty(value); err != nil {
+			return nil, fmt.Errorf("properties: line %d: %s", n+1, err)
+		}
+		output[key] = value
+	}
+	return output, nil
+}
+
+// reports whether line ends in an odd number of backslashes
+func continues(line string) bool {
+	backslashes := 0
+	for i := len(line) - 1; i >= 0 && line[i] == '\\'; i-- {
+		backslashes++
+	}
+	return backslashes%2 == 1
+}
+
+// splits a logical line to the escaped key and value at the first unescaped =, : or whitespace
+func splitProperty(line string) (string, string) {
+	for i := 0; i < len(line); i++ {
+		switch line[i] {
+		case '\\':
+			i++
+		case '=', ':':
+			return line[:i], strings.TrimLeft(line[i+1:], " \t\f")
+		case ' ', '\t', '\f':
+			value := strings.TrimLeft(line[i:], " \t\f")
+			if value != "" && (value[0] == '=' || value[0] == ':') {
+				value = strings.TrimLeft(value[1:], " \t\f")
+			}
+			return line[:i], value
+		}
+	}
+	return line, ""
+}
+
+func unescapeProperty(s string) (string, error) {
+	if !strings.Contains(s, `\`) {
+		return s, nil
+	}
+	var buffer bytes.Buffer
+	var surrogate rune
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c != '\\' || i+1 == len(s) {
+			buffer.WriteByte(c)
+			continue
+		}
+		i++
+		switch s[i] {
+		case 't':
+			buffer.WriteByte('\t')
+		case 'n':
+			buffer.WriteByte('\n')
+		case 'r':
+			buffer.WriteByte('\r')
+		case 'f':
+			buffer.WriteByte('\f')
+		case 'u':
+			if i+5 > len(s) {
+				return "", fmt.Errorf("malformed \\uxxxx escape in %q", s)
+			}
+			code, err := strconv.ParseUint(s[i+1:i+5], 16, 16)
+			if err != nil {
+				return "", fmt.Errorf("malformed \\uxxxx escape in %q", s)
+			}
+			i += 4
+			r := rune(code)
+			// combine utf-16 surrogate pairs
+			if utf16.IsSurrogate(r) && surrogate == 0 {
+				surrogate = r
+				continue
+			}
+			if surrogate != 0 {
+				r = utf16.DecodeRune(surrogate, r)
+				surrogate = 0
+			}
+			buffer.WriteRune(r)
+		default:
+			buffer.WriteByte(s[i])
+		}
+	}
+	return buffer.String(), nil
+}
+
+// escapes s so it loads back as is, keys also escape the separators and whitespace
+func escapeProperty(s string, key bool) string {
+	var buffer bytes.Buffer
+	for i, r := range s {
+		switch {
+		case r == '\\':
+			buffer.WriteString(`\\`)
+		case r == '\t':
+			buffer.WriteString(`\t`)
+		case r == '\n':
+			buffer.WriteString(`\n`)
+		case r == '\r':
+			buffer.WriteString(`\r`)
+		case r == '\f':
+			buffer.WriteString(`\f`)
+		case r == ' ' && (key || i == 0):
+			buffer.WriteString(`\ `)
+		case strings.ContainsRune("=:#!", r) && (key || i == 0):
+			buffer.WriteRune('\\')
+			buffer.WriteRune(r)
+		case r < 0x20 || r > 0x7e:
+			for _, c := range utf16.Encode([]rune{r}) {
+				buffer.WriteString(fmt.Sprintf(`\u%04x`, c))
+			}
+		default:
+			buffer.WriteRune(r)
+		}
+	}
+	return buffer.String()
+}
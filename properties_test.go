@@ -0,0 +1,77 @@
+package gonfig_test
+
+import (
+	. "github.com/Nomon/gonfig"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"io/ioutil"
+	"os"
+)
+
+var _ = Describe("PropertiesConfig", func() {
+	var (
+		err error
+		cfg WritableConfig
+	)
+
+	BeforeEach(func() {
+		cfg, err = OpenPropertiesConfig("./config_valid.properties", "")
+	})
+
+	It("Should not error", func() {
+		Expect(err).NotTo(HaveOccurred())
+	})
+	It("Should load keys with all separators", func() {
+		Expect(cfg.Get("test")).To(Equal("123"))
+		Expect(cfg.Get("db.host")).To(Equal("localhost"))
+		Expect(cfg.Get("db.port")).To(Equal("5432"))
+		Expect(cfg.Get("indented.key")).To(Equal("whitespace separated"))
+		value, ok := cfg.(LookupConfigurable).Lookup("empty")
+		Expect(ok).To(BeTrue())
+		Expect(value).To(Equal(""))
+	})
+	It("Should unescape keys and values", func() {
+		Expect(cfg.Get("with space=and:colon")).To(Equal("value"))
+		Expect(cfg.Get("escapes")).To(Equal("tab\there\nnewline ä 😀"))
+	})
+	It("Should join continuation lines", func() {
+		Expect(cfg.Get("multiline")).To(Equal("first second third"))
+	})
+	It("Should skip comments", func() {
+		Expect(cfg.All()).To(HaveLen(8))
+	})
+	It("Should map the separator to ':'", func() {
+		cfg, err = OpenPropertiesConfig("./config_valid.properties", ".")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(cfg.Get("db:host")).To(Equal("localhost"))
+		Expect(cfg.Get("indented:key")).To(Equal("whitespace separated"))
+	})
+	It("Should error for invalid files", func() {
+		cfg, err = OpenPropertiesConfig("./config_invalid.properties", "")
+		Expect(err).To(HaveOccurred())
+		cfg, err = OpenPropertiesConfig("./config_nonexisting.properties", "")
+		Expect(os.IsNotExist(err)).To(BeTrue())
+	})
+
+	Describe("Save", func() {
+		path := "./config_test.properties"
+		AfterEach(func() {
+			os.Remove(path)
+		})
+		It("Should escape keys and values so they load back the same", func() {
+			saved := NewPropertiesConfig(path, "")
+			saved.Reset(cfg.All())
+			saved.Set(" leading", " #!value")
+			Expect(saved.Save()).To(Succeed())
+			Expect(NewPropertiesConfig(path, "").All()).To(Equal(saved.All()))
+		})
+		It("Should save ':' keys with the separator", func() {
+			saved := NewPropertiesConfig(path, ".")
+			saved.Reset(map[string]string{"db:host": "localhost", "db:port": "5432"})
+			Expect(saved.Save()).To(Succeed())
+			data, err := ioutil.ReadFile(path)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(data)).To(Equal("db.host=localhost\ndb.port=5432\n"))
+		})
+	})
+})
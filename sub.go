@@ -0,0 +1,96 @@
+package gonfig
+
+import "strings"
+
+// SubConfig is a view of the keys under a prefix of a Gonfig hierarchy,
+// keys of the view are the keys of the hierarchy with the prefix removed.
+type SubConfig struct {
+	parent *Gonfig
+	// prefix of the keys including the trailing ":"
+	prefix string
+}
+
+// Ensure SubConfig implements LookupConfigurable and ObservableConfigurable
+var (
+	_ LookupConfigurable     = (*SubConfig)(nil)
+	_ ObservableConfigurable = (*SubConfig)(nil)
+)
+
+// Returns a view of the keys under prefix, so conf.Sub("database").Get("host") is conf.Get("database:host").
+// The view resolves keys through the whole hierarchy, overrides, mounts and defaults, and Set sets the
+// prefixed key in the overrides of the hierarchy.
+func (self *Gonfig) Sub(prefix string) *SubConfig {
+	return &SubConfig{parent: self, prefix: subPrefix("", prefix)}
+}
+
+// Returns a view of the keys under prefix within this view
+func (self *SubConfig) Sub(prefix string) *SubConfig {
+	return &SubConfig{parent: self.parent, prefix: subPrefix(self.prefix, prefix)}
+}
+
+// Returns the prefix of the view without the trailing ":"
+func (self *SubConfig) Prefix() string {
+	return strings.TrimSuffix(self.prefix, ":")
+}
+
+// Gets the prefixed key from the hierarchy
+func (self *SubConfig) Get(key string) string {
+	return self.parent.Get(self.prefix + key)
+}
+
+// Looks up the prefixed key from the hierarchy
+func (self *SubConfig) Lookup(key string) (string, bool) {
+	return self.parent.Lookup(self.prefix + key)
+}
+
+// Sets the prefixed key in the overrides of the hierarchy
+func (self *SubConfig) Set(key, value string) {
+	self.parent.Set(self.prefix+key, value)
+}
+
+// Replaces the keys under the prefix in the overrides of the hierarchy with data,
+// the other overrides are kept. If no data is given the keys under the prefix are removed.
+func (self *SubConfig) Reset(datas ...map[string]string) {
+	values := make(map[string]string)
+	for key, value := range self.parent.Configurable.All() {
+		if !strings.HasPrefix(key, self.prefix) {
+			values[key] = value
+		}
+	}
+	for _, data := range datas {
+		for key, value := range data {
+			values[self.prefix+key] = value
+		}
+	}
+	self.parent.Configurable.Reset(values)
+}
+
+// Returns the resolved values of the keys under the prefix with the prefix removed
+func (self *SubConfig) All() map[string]string {
+	values := make(map[string]string)
+	for key, value := range self.parent.All() {
+		if strings.HasPrefix(key, self.prefix) {
+			values[strings.TrimPrefix(key, self.prefix)] = value
+		}
+	}
+	return values
+}
+
+// Observe changes to the hierarchy
+func (self *SubConfig) Observe(fn func()) func() {
+	return self.parent.Observe(fn)
+}
+
+// Marshal the keys under the prefix into target, see MarshalConfig
+func (self *SubConfig) Marshal(target interface{}) error {
+	return MarshalConfig(self, target)
+}
+
+// joins prefix to the prefix of a parent view, the result ends in ":"
+func subPrefix(parent, prefix string) string {
+	prefix = strings.Trim(prefix, ":")
+	if prefix == "" {
+		return parent
+	}
+	return parent + prefix + ":"
+}
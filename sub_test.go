@@ -0,0 +1,66 @@
+package gonfig_test
+
+import (
+	. "github.com/Nomon/gonfig"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("SubConfig", func() {
+	var (
+		cfg *Gonfig
+		sub *SubConfig
+	)
+	BeforeEach(func() {
+		cfg = NewConfig(nil)
+		cfg.Use("local", NewMemoryConfig()).Reset(map[string]string{
+			"database:host":         "localhost",
+			"database:replica:host": "replica",
+			"other":                 "value",
+		})
+		cfg.Defaults.Set("database:port", "5432")
+		cfg.Set("database:user", "admin")
+		sub = cfg.Sub("database")
+	})
+	It("Should resolve prefixed keys through the hierarchy", func() {
+		Expect(sub.Prefix()).To(Equal("database"))
+		Expect(sub.Get("host")).To(Equal("localhost"))
+		Expect(sub.Get("port")).To(Equal("5432"))
+		Expect(sub.Get("user")).To(Equal("admin"))
+		Expect(sub.Get("other")).To(Equal(""))
+		Expect(sub.All()).To(Equal(map[string]string{
+			"host":         "localhost",
+			"replica:host": "replica",
+			"port":         "5432",
+			"user":         "admin",
+		}))
+	})
+	It("Should set prefixed keys in the overrides", func() {
+		sub.Set("host", "remote")
+		Expect(cfg.Get("database:host")).To(Equal("remote"))
+		Expect(cfg.Use("local").Get("database:host")).To(Equal("localhost"))
+	})
+	It("Should reset only the keys under the prefix", func() {
+		cfg.Set("other", "override")
+		sub.Reset(map[string]string{"name": "app"})
+		Expect(cfg.Get("database:name")).To(Equal("app"))
+		Expect(cfg.Get("database:user")).To(Equal(""))
+		Expect(cfg.Get("other")).To(Equal("override"))
+	})
+	It("Should nest views", func() {
+		Expect(sub.Sub("replica").Get("host")).To(Equal("replica"))
+		Expect(cfg.Sub("database:replica:").Get("host")).To(Equal("replica"))
+	})
+	It("Should marshal the keys under the prefix", func() {
+		var target struct {
+			Host string `gonfig:"host"`
+			Port int    `gonfig:"port"`
+			Name string `gonfig:"name" default:"app"`
+		}
+		Expect(sub.Marshal(&target)).To(Succeed())
+		Expect(target.Host).To(Equal("localhost"))
+		Expect(target.Port).To(Equal(5432))
+		Expect(target.Name).To(Equal("app"))
+		Expect(GetInt(sub, "port")).To(Equal(5432))
+	})
+})
@@ -0,0 +1,247 @@
+package gonfig
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+)
+
+// TomlConfig is a WritableConfig backed by a toml file.
+// Tables are flattened into ":" separated keys and arrays into comma separated
+// values like JsonConfig does, arrays of tables or arrays are flattened with the index of
+// the element as a key segment, so [[servers]] name = "a" is found from "servers:0:name".
+// The toml types of the loaded values are remembered, so Save writes them back with their native types.
+type TomlConfig struct {
+	FileConfig
+	// toml types of the loaded keys
+	types MemoryConfig
+}
+
+// the toml types of flattened values
+const (
+	tomlString   = "string"
+	tomlInteger  = "integer"
+	tomlFloat    = "float"
+	tomlBoolean  = "boolean"
+	tomlDatetime = "datetime"
+	// dates and times without an offset, named like the locations the toml decoder gives them
+	tomlLocalDatetime = "datetime-local"
+	tomlLocalDate     = "date-local"
+	tomlLocalTime     = "time-local"
+	// array of values, the type of the elements follows the prefix, it is empty if the types are mixed
+	tomlArray = "array:"
+	// array of tables or arrays, set for the key of the array, its elements are flattened with their index
+	// as a key segment
+	tomlTables = "tables"
+)
+
+// the layouts of the toml date and time types
+var tomlLayouts = map[string]string{
+	tomlDatetime:      time.RFC3339Nano,
+	tomlLocalDatetime: "2006-01-02T15:04:05.999999999",
+	tomlLocalDate:     "2006-01-02",
+	tomlLocalTime:     "15:04:05.999999999",
+}
+
+// a value the toml encoder writes as it is, local dates and times are saved as tomlLiterals
+// as the encoder writes time.Time values with an offset
+type tomlLiteral string
+
+func (self tomlLiteral) MarshalTOML() ([]byte, error) {
+	return []byte(self), nil
+}
+
+// Returns a new WritableConfig backed by a toml file at path.
+// The file does not need to exist, if it does not exist the first Save call will create it.
+// Errors loading the file are not returned, use OpenTomlConfig or the error from Load
+// or Gonfig.Use to detect a missing or invalid file.
+func NewTomlConfig(path string, cfg ...Configurable) WritableConfig {
+	conf, _ := OpenTomlConfig(path, cfg...)
+	return conf
+}
+
+// Returns a new WritableConfig backed by a toml file at path like NewTomlConfig,
+// along with the error from loading the file.
+func OpenTomlConfig(path string, cfg ...Configurable) (WritableConfig, error) {
+	conf := &TomlConfig{FileConfig: openFile(path, cfg)}
+	return conf, LoadConfig(conf)
+}
+
+// Attempts to load the toml configuration at TomlConfig.Path
+// and Set them into the underlaying Configurable
+func (self *TomlConfig) Load() error {
+	data, err := ioutil.ReadFile(self.Path)
+	if err != nil {
+		return err
+	}
+	out, types, err := unmarshalToml(data)
+	if err != nil {
+		return err
+	}
+	self.types.Reset(types)
+	self.Configurable.Reset(out)
+	return nil
+}
+
+// Attempts to save the configuration from the underlaying Configurable to the toml file at TomlConfig.Path,
+// ":" separated keys are saved as tables and loaded values keep their toml types.
+// Values that were not loaded from the file are saved as strings.
+func (self *TomlConfig) Save() error {
+	types := self.types.All()
+	nested := nestKeys(self.Configurable.All(), func(key, value string) interface{} {
+		return tomlValue(value, types[key])
+	})
+	arraysFromIndexes(nested, types, tomlTables)
+	var buffer bytes.Buffer
+	if err := toml.NewEncoder(&buffer).Encode(nested); err != nil {
+		return err
+	}
+	return writeFile(self.Path, buffer.Bytes(), 0)
+}
+
+// Reloads the config whenever the toml file changes, see Watcher
+func (self *TomlConfig) Watch(interval time.Duration) *Watcher {
+	return watchFile(self, self.Path, interval)
+}
+
+// flattens a toml document, returns the flattened values and their toml types
+func unmarshalToml(data []byte) (map[string]string, map[string]string, error) {
+	document := make(map[string]interface{})
+	if _, err := toml.Decode(string(data), &document); err != nil {
+		return nil, nil, err
+	}
+	output := make(map[string]string)
+	types := make(map[string]string)
+	unmarshalTomlSegment(document, "", output, types)
+	return output, types, nil
+}
+
+func unmarshalTomlSegment(segment map[string]interface{}, segmentPath string, output, types map[string]string) {
+	if segmentPath != "" {
+		segmentPath += ":"
+	}
+	for k, v := range segment {
+		keyWithPath := segmentPath + k
+		switch v := v.(type) {
+		case map[string]interface{}:
+			unmarshalTomlSegment(v, keyWithPath, output, types)
+		case []map[string]interface{}:
+			types[keyWithPath] = tomlTables
+			for i, table := range v {
+				unmarshalTomlSegment(table, keyWithPath+":"+strconv.Itoa(i), output, types)
+			}
+		case []interface{}:
+			if indexed, ok := jsonIndexed(v); ok {
+				types[keyWithPath] = tomlTables
+				unmarshalTomlSegment(indexed, keyWithPath, output, types)
+				continue
+			}
+			values := make([]string, len(v))
+			typ := ""
+			for i, value := range v {
+				values[i] = formatToml(value)
+				if i == 0 || typ == tomlType(value) {
+					typ = tomlType(value)
+				} else {
+					typ = ""
+				}
+			}
+			output[keyWithPath] = strings.Join(values, ",")
+			types[keyWithPath] = tomlArray + typ
+		default:
+			output[keyWithPath] = formatToml(v)
+			types[keyWithPath] = tomlType(v)
+		}
+	}
+}
+
+// formats a toml value as a string
+func formatToml(value interface{}) string {
+	if t, ok := value.(time.Time); ok {
+		return t.Format(tomlLayouts[tomlType(t)])
+	}
+	return fmt.Sprintf("%v", value)
+}
+
+// returns the toml type of a decoded value
+func tomlType(value interface{}) string {
+	switch value := value.(type) {
+	case int64:
+		return tomlInteger
+	case float64:
+		return tomlFloat
+	case bool:
+		return tomlBoolean
+	case time.Time:
+		switch name := value.Location().String(); name {
+		case tomlLocalDatetime, tomlLocalDate, tomlLocalTime:
+			return name
+		}
+		return tomlDatetime
+	}
+	return tomlString
+}
+
+// converts a flattened value back to a value of the toml type typ, the elements of mixed arrays
+// are inferred. Values that do not parse as typ are kept as strings.
+func tomlValue(value, typ string) interface{} {
+	if strings.HasPrefix(typ, tomlArray) {
+		values := []interface{}{}
+		if value == "" {
+			return values
+		}
+		elem := strings.TrimPrefix(typ, tomlArray)
+		for _, v := range strings.Split(value, ",") {
+			if elem == "" {
+				values = append(values, inferToml(v))
+				continue
+			}
+			values = append(values, tomlValue(v, elem))
+		}
+		return values
+	}
+	var converted interface{}
+	var err error
+	switch typ {
+	case tomlInteger:
+		converted, err = strconv.ParseInt(value, 10, 64)
+	case tomlFloat:
+		converted, err = strconv.ParseFloat(value, 64)
+	case tomlBoolean:
+		converted, err = strconv.ParseBool(value)
+	case tomlDatetime:
+		converted, err = time.Parse(time.RFC3339Nano, value)
+	case tomlLocalDatetime, tomlLocalDate, tomlLocalTime:
+		var t time.Time
+		if t, err = time.Parse(tomlLayouts[typ], value); err == nil {
+			converted = tomlLiteral(t.Format(tomlLayouts[typ]))
+		}
+	default:
+		return value
+	}
+	if err != nil {
+		return value
+	}
+	return converted
+}
+
+// converts an element of a mixed array back to an integer, float or boolean if it looks like one
+func inferToml(value string) interface{} {
+	switch {
+	case value == "true" || value == "false":
+		return value == "true"
+	case jsonNumberPattern.MatchString(value):
+		if i, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return i
+		}
+		if f, err := strconv.ParseFloat(value, 64); err == nil {
+			return f
+		}
+	}
+	return value
+}
@@ -0,0 +1,132 @@
+package gonfig_test
+
+import (
+	"github.com/BurntSushi/toml"
+	. "github.com/Nomon/gonfig"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"io/ioutil"
+	"os"
+	"strings"
+)
+
+var _ = Describe("TomlConfig", func() {
+	var (
+		err error
+		cfg WritableConfig
+	)
+
+	BeforeEach(func() {
+		cfg, err = OpenTomlConfig("./config_valid.toml")
+	})
+
+	Context("When the TOML config loads properly", func() {
+		It("Should not error", func() {
+			Expect(err).NotTo(HaveOccurred())
+		})
+		It("Should have the scalar values", func() {
+			Expect(cfg.Get("test")).To(Equal("123"))
+			Expect(cfg.Get("test_number")).To(Equal("1"))
+			Expect(cfg.Get("test_bool")).To(Equal("true"))
+			Expect(cfg.Get("test_float")).To(Equal("12.34"))
+			Expect(cfg.Get("test_date")).To(Equal("1979-05-27T07:32:00Z"))
+			Expect(cfg.Get("test_array")).To(Equal("1,2,3"))
+		})
+		It("Should flatten tables and nested tables like JsonConfig", func() {
+			Expect(cfg.Get("test_object:nested_string")).To(Equal("abcd"))
+			Expect(cfg.Get("test_object:nested_int")).To(Equal("987"))
+			Expect(cfg.Get("double_nested:nested_object:test_inner")).To(Equal("foo"))
+		})
+		It("Should flatten arrays of tables by index", func() {
+			Expect(cfg.Get("servers:0:name")).To(Equal("alpha"))
+			Expect(cfg.Get("servers:0:ports")).To(Equal("8000,8001"))
+			Expect(cfg.Get("servers:1:name")).To(Equal("beta"))
+		})
+		It("Should be usable in a hierarchy", func() {
+			conf := NewConfig(nil)
+			conf.Use("toml", cfg)
+			Expect(conf.Get("servers:1:name")).To(Equal("beta"))
+			Expect(conf.Explain("test").Origin).To(Equal("./config_valid.toml"))
+		})
+	})
+
+	Context("When the TOML config fails to load", func() {
+		It("should error for invalid toml", func() {
+			cfg, err = OpenTomlConfig("./config_invalid.toml")
+			Expect(err).To(HaveOccurred())
+			cfg.Set("QQ", "123")
+			Expect(cfg.Get("QQ")).To(Equal("123"))
+		})
+		It("should error for a missing file", func() {
+			cfg, err = OpenTomlConfig("./config_nonexisting.toml")
+			Expect(os.IsNotExist(err)).To(BeTrue())
+		})
+	})
+
+	Describe("Save", func() {
+		path := "./config_test.toml"
+		AfterEach(func() {
+			os.Remove(path)
+		})
+		It("Should preserve native types, tables and arrays of tables", func() {
+			data, err := ioutil.ReadFile("./config_valid.toml")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ioutil.WriteFile(path, data, 0600)).To(Succeed())
+			saved := NewTomlConfig(path)
+			saved.Set("servers:1:name", "gamma")
+			saved.Set("added", "value")
+			Expect(saved.Save()).To(Succeed())
+
+			var original, document map[string]interface{}
+			_, err = toml.DecodeFile("./config_valid.toml", &original)
+			Expect(err).ToNot(HaveOccurred())
+			_, err = toml.DecodeFile(path, &document)
+			Expect(err).ToNot(HaveOccurred())
+			original["servers"].([]map[string]interface{})[1]["name"] = "gamma"
+			original["added"] = "value"
+			Expect(document).To(Equal(original))
+		})
+		It("Should preserve local dates and times, mixed and nested arrays", func() {
+			document := strings.Join([]string{
+				"date = 1979-05-27",
+				"time = 07:32:00.5",
+				"local = 1979-05-27T07:32:00",
+				"numbers = [1, 2.5]",
+				"mixed = [1, \"a\", true]",
+				"matrix = [[1, 2], [3], []]",
+				"cells = [[[\"a\"]], [{x = 1}, \"c\"]]",
+			}, "\n")
+			Expect(ioutil.WriteFile(path, []byte(document), 0600)).To(Succeed())
+			saved := NewTomlConfig(path)
+			Expect(saved.Get("date")).To(Equal("1979-05-27"))
+			Expect(saved.Get("time")).To(Equal("07:32:00.5"))
+			Expect(saved.Get("local")).To(Equal("1979-05-27T07:32:00"))
+			Expect(saved.Get("numbers")).To(Equal("1,2.5"))
+			Expect(saved.Get("matrix:0")).To(Equal("1,2"))
+			Expect(saved.Get("cells:1:0:x")).To(Equal("1"))
+			Expect(saved.Save()).To(Succeed())
+
+			data, err := ioutil.ReadFile(path)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(data)).To(ContainSubstring("date = 1979-05-27\n"))
+			Expect(string(data)).To(ContainSubstring("time = 07:32:00.5\n"))
+			Expect(string(data)).To(ContainSubstring("local = 1979-05-27T07:32:00\n"))
+			var original, decoded map[string]interface{}
+			_, err = toml.Decode(document, &original)
+			Expect(err).ToNot(HaveOccurred())
+			_, err = toml.Decode(string(data), &decoded)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(decoded).To(Equal(original))
+			Expect(NewTomlConfig(path).All()).To(Equal(saved.All()))
+		})
+		It("Should save new keys as strings in tables", func() {
+			saved := NewTomlConfig(path)
+			saved.Reset(map[string]string{"db:host": "localhost", "db:port": "5432"})
+			Expect(saved.Save()).To(Succeed())
+			data, err := ioutil.ReadFile(path)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(data)).To(Equal("[db]\n  host = \"localhost\"\n  port = \"5432\"\n"))
+			Expect(NewTomlConfig(path).All()).To(Equal(saved.All()))
+		})
+	})
+})
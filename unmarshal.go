@@ -0,0 +1,190 @@
+package gonfig
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+
+// Sets the flattened gonfig tagged fields of source into the overrides of the hierarchy, see FromStruct.
+func (self *Gonfig) Unmarshal(source interface{}) error {
+	return UnmarshalConfig(self, source)
+}
+
+// Sets the flattened gonfig tagged fields of the struct source into config, see FromStruct.
+// Building Defaults from a typed value:
+// UnmarshalConfig(conf.Defaults, DefaultConfig{Database: Database{Port: 5432}})
+func UnmarshalConfig(config Configurable, source interface{}) error {
+	values, err := FromStruct(source)
+	if err != nil {
+		return err
+	}
+	for key, value := range values {
+		config.Set(key, value)
+	}
+	return nil
+}
+
+// Flattens the gonfig tagged fields of the struct or struct pointer source into keys and values
+// that Marshal decodes back into the same struct. Nested structs are flattened to keys prefixed with
+// the key of the struct and ":", slices of structs are prefixed with the index of the struct,
+// maps are flattened to the keys under the key of the map and other slices are comma separated values.
+// Values are formatted with encoding.TextMarshaler when implemented, time.Duration with its String.
+// Nil pointers, slices and maps are skipped, zero values are skipped if the tag has the omitempty
+// option, ie. gonfig:"name,omitempty".
+func FromStruct(source interface{}) (map[string]string, error) {
+	value := reflect.ValueOf(source)
+	for value.Kind() == reflect.Ptr && !value.IsNil() {
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("gonfig: FromStruct source must be a struct or a non nil pointer to a struct, got %T", source)
+	}
+	values := make(map[string]string)
+	if err := encodeStruct(value, "", "", values); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+func encodeStruct(value reflect.Value, prefix, path string, values map[string]string) error {
+	typ := value.Type()
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if field.PkgPath != "" && !field.Anonymous {
+			continue
+		}
+		name := fieldKey(field)
+		if name == "-" {
+			continue
+		}
+		fieldValue := value.Field(i)
+		if name == "" {
+			// embedded structs share the prefix of the struct they are embedded in
+			if !field.Anonymous || indirectType(field.Type).Kind() != reflect.Struct || isText(field.Type) {
+				continue
+			}
+			if fieldValue.Kind() == reflect.Ptr {
+				if fieldValue.IsNil() {
+					continue
+				}
+				fieldValue = fieldValue.Elem()
+			}
+			if err := encodeStruct(fieldValue, prefix, joinField(path, field.Name), values); err != nil {
+				return err
+			}
+			continue
+		}
+		if field.PkgPath != "" || omitEmpty(field) && fieldValue.IsZero() {
+			continue
+		}
+		if err := encode(fieldValue, prefix+name, joinField(path, field.Name), values); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// flattens value into values as key or the keys nested under key
+func encode(value reflect.Value, key, path string, values map[string]string) error {
+	for value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return nil
+		}
+		value = value.Elem()
+	}
+	typ := value.Type()
+	switch {
+	case typ.Implements(textMarshalerType):
+	case typ.Kind() == reflect.Struct:
+		return encodeStruct(value, key+":", path, values)
+	case typ.Kind() == reflect.Map:
+		if value.IsNil() {
+			return nil
+		}
+		if typ.Key().Kind() != reflect.String {
+			return &KeyError{Key: key, Field: path, Err: fmt.Errorf("unsupported map key type %s", typ.Key())}
+		}
+		names := make([]string, 0, value.Len())
+		for _, name := range value.MapKeys() {
+			names = append(names, name.String())
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			elem := value.MapIndex(reflect.ValueOf(name).Convert(typ.Key()))
+			if err := encode(elem, key+":"+name, path+"["+name+"]", values); err != nil {
+				return err
+			}
+		}
+		return nil
+	case typ.Kind() == reflect.Slice && isNested(typ.Elem()):
+		for i := 0; i < value.Len(); i++ {
+			if err := encode(value.Index(i), key+":"+strconv.Itoa(i), path+"["+strconv.Itoa(i)+"]", values); err != nil {
+				return err
+			}
+		}
+		return nil
+	case typ.Kind() == reflect.Slice && value.IsNil():
+		return nil
+	}
+	formatted, err := encodeValue(value)
+	if err != nil {
+		return &KeyError{Key: key, Field: path, Err: err}
+	}
+	values[key] = formatted
+	return nil
+}
+
+// formats value so that decodeValue parses it back
+func encodeValue(value reflect.Value) (string, error) {
+	typ := value.Type()
+	if typ.Implements(textMarshalerType) {
+		text, err := value.Interface().(encoding.TextMarshaler).MarshalText()
+		return string(text), err
+	}
+	if typ == durationType {
+		return time.Duration(value.Int()).String(), nil
+	}
+	switch typ.Kind() {
+	case reflect.Bool:
+		return strconv.FormatBool(value.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(value.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(value.Uint(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(value.Float(), 'g', -1, typ.Bits()), nil
+	case reflect.Complex64, reflect.Complex128:
+		return strconv.FormatComplex(value.Complex(), 'g', -1, typ.Bits()), nil
+	case reflect.String:
+		return value.String(), nil
+	case reflect.Slice:
+		parts := make([]string, value.Len())
+		for i := range parts {
+			elem := reflect.Indirect(value.Index(i))
+			if !elem.IsValid() {
+				continue
+			}
+			part, err := encodeValue(elem)
+			if err != nil {
+				return "", err
+			}
+			parts[i] = part
+		}
+		return strings.Join(parts, ","), nil
+	}
+	return "", errors.New("unsupported type " + typ.String())
+}
+
+// reports whether the gonfig tag of field has the omitempty option
+func omitEmpty(field reflect.StructField) bool {
+	options := strings.Split(field.Tag.Get("gonfig"), ",")[1:]
+	return contains(options, "omitempty")
+}
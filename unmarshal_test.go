@@ -0,0 +1,91 @@
+package gonfig_test
+
+import (
+	"net"
+	"os"
+	"time"
+
+	. "github.com/Nomon/gonfig"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Unmarshal", func() {
+	var source marshalConfig
+	BeforeEach(func() {
+		optional := 5
+		source = marshalConfig{
+			Name:     "app",
+			Debug:    true,
+			Workers:  4,
+			Ratio:    0.5,
+			Timeout:  2 * time.Second,
+			Started:  time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC),
+			IP:       net.ParseIP("127.0.0.1"),
+			Tags:     []string{"a", "b"},
+			Labels:   map[string]string{"env": "prod"},
+			Optional: &optional,
+			Cache:    &marshalServer{Name: "redis"},
+			Servers:  []marshalServer{{"a", 8080}, {"b", 0}},
+			Named:    map[string]marshalServer{"primary": {Name: "p"}},
+			Skipped:  "skipped",
+			Untagged: "untagged",
+		}
+		source.Burst = 10
+		source.Database.Host = "localhost"
+	})
+	It("Should flatten the tagged fields", func() {
+		values, err := FromStruct(&source)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(values).To(HaveKeyWithValue("burst", "10"))
+		Expect(values).To(HaveKeyWithValue("debug", "true"))
+		Expect(values).To(HaveKeyWithValue("size", "0"))
+		Expect(values).To(HaveKeyWithValue("ratio", "0.5"))
+		Expect(values).To(HaveKeyWithValue("timeout", "2s"))
+		Expect(values).To(HaveKeyWithValue("started", "2020-01-02T03:04:05Z"))
+		Expect(values).To(HaveKeyWithValue("ip", "127.0.0.1"))
+		Expect(values).To(HaveKeyWithValue("tags", "a,b"))
+		Expect(values).To(HaveKeyWithValue("labels:env", "prod"))
+		Expect(values).To(HaveKeyWithValue("optional", "5"))
+		Expect(values).To(HaveKeyWithValue("db:host", "localhost"))
+		Expect(values).To(HaveKeyWithValue("cache:name", "redis"))
+		Expect(values).To(HaveKeyWithValue("servers:1:name", "b"))
+		Expect(values).To(HaveKeyWithValue("named:primary:name", "p"))
+		Expect(values).ToNot(HaveKey("missing"))
+		Expect(values).ToNot(HaveKey("ports"))
+		Expect(values).ToNot(HaveKey("-"))
+		Expect(values).ToNot(HaveKey("Untagged"))
+	})
+	It("Should skip empty values with omitempty", func() {
+		var omitted struct {
+			Name  string `gonfig:"name,omitempty"`
+			Count int    `gonfig:"count,omitempty"`
+			Kept  int    `gonfig:"kept"`
+		}
+		Expect(FromStruct(omitted)).To(Equal(map[string]string{"kept": "0"}))
+	})
+	It("Should round trip through Marshal", func() {
+		cfg := NewConfig(nil)
+		Expect(cfg.Unmarshal(source)).To(Succeed())
+		var target marshalConfig
+		Expect(cfg.Marshal(&target)).To(Succeed())
+		source.Skipped, source.Untagged = "", ""
+		Expect(target).To(Equal(source))
+	})
+	It("Should seed Defaults that can be saved", func() {
+		path := "./config_unmarshal.json"
+		defer os.Remove(path)
+		cfg := NewConfig(nil)
+		Expect(UnmarshalConfig(cfg.Defaults, source)).To(Succeed())
+		Expect(cfg.Get("db:host")).To(Equal("localhost"))
+		json := NewJsonConfig(path)
+		json.Reset(cfg.All())
+		Expect(json.Save()).To(Succeed())
+		Expect(NewJsonConfig(path).Get("servers:0:port")).To(Equal("8080"))
+	})
+	It("Should error for sources that are not structs", func() {
+		_, err := FromStruct("string")
+		Expect(err).To(HaveOccurred())
+		Expect(UnmarshalConfig(NewMemoryConfig(), nil)).ToNot(Succeed())
+	})
+})
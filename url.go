@@ -1,34 +1,168 @@
 package gonfig
 
 import (
+	"context"
 	"io/ioutil"
+	"maps"
 	"net/http"
+	"sync"
+	"time"
 )
 
+// The timeout of fetching documents with the default client of UrlConfig
+const DefaultUrlTimeout = 30 * time.Second
+
+// the client used by UrlConfigs that do not have a Client
+var defaultUrlClient = &http.Client{Timeout: DefaultUrlTimeout}
+
+// UrlConfig is a ReadableConfig backed by a document fetched from Url.
+// The document is decoded with the registered Format called Format if it is set, otherwise
+// the format is chosen by the Content-Type of the response or the extension of the Url path,
+// falling back to json, see RegisterFormat.
+// Responses with a status code other than 2xx fail the Load with a *StatusError
+// and leave the loaded config as it was.
+// The ETag and Last-Modified headers of the response are sent back in If-None-Match and If-Modified-Since
+// headers of the next Load, so a document the server reports as not modified is not downloaded again,
+// the last loaded document is applied again instead in case the config was changed since it was loaded.
 type UrlConfig struct {
 	Configurable
-	url string
+	Url string
+	// Name of the registered Format the document is decoded with regardless of its Content-Type,
+	// ie. "yaml", the first media type of the format is sent in the Accept header
+	Format string
+	// Client used to fetch the document, a client with DefaultUrlTimeout is used if Client is nil.
+	// Custom TLS roots and proxies can be configured with the Transport of the client.
+	Client *http.Client
+	// Headers added to the request
+	Header http.Header
+	// Credentials for basic auth, used if Username is set
+	Username string
+	Password string
+	// Bearer token sent in the Authorization header, used if Token is set
+	Token string
+
+	// guards the last loaded document and its validators
+	mu sync.Mutex
+	// the url the document and validators are for
+	validated string
+	// the last loaded document, never modified after it is loaded
+	document     map[string]string
+	etag         string
+	lastModified string
 }
 
-// Returns a new Configurable backed by JSON at url
+// Returns a new Configurable backed by the document at url, the format of the document
+// is detected from the Content-Type of the response or the extension of url
 func NewUrlConfig(url string) ReadableConfig {
-	return &UrlConfig{NewMemoryConfig(), url}
+	return &UrlConfig{Configurable: NewMemoryConfig(), Url: url}
 }
 
+// Fetches the document at Url and loads it into the underlaying Configurable
 func (self *UrlConfig) Load() error {
-	resp, err := http.Get(self.url)
+	return self.LoadContext(context.Background())
+}
+
+// Fetches the document at Url like Load, the request is canceled when ctx is done
+func (self *UrlConfig) LoadContext(ctx context.Context) error {
+	var format *Format
+	if self.Format != "" {
+		var err error
+		if format, err = namedFormat(self.Format); err != nil {
+			return err
+		}
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, self.Url, nil)
+	if err != nil {
+		return err
+	}
+	for name, values := range self.Header {
+		for _, value := range values {
+			req.Header.Add(name, value)
+		}
+	}
+	if format != nil && len(format.MediaTypes) > 0 && req.Header.Get("Accept") == "" {
+		req.Header.Set("Accept", format.MediaTypes[0])
+	}
+	if self.Username != "" {
+		req.SetBasicAuth(self.Username, self.Password)
+	}
+	if self.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+self.Token)
+	}
+	self.mu.Lock()
+	if self.validated == self.Url {
+		if self.etag != "" {
+			req.Header.Set("If-None-Match", self.etag)
+		}
+		if self.lastModified != "" {
+			req.Header.Set("If-Modified-Since", self.lastModified)
+		}
+	}
+	self.mu.Unlock()
+	client := self.Client
+	if client == nil {
+		client = defaultUrlClient
+	}
+	resp, err := client.Do(req)
 	if err != nil {
 		return err
 	}
 	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotModified {
+		self.mu.Lock()
+		document := self.document
+		self.mu.Unlock()
+		if !maps.Equal(self.All(), document) {
+			self.Reset(document)
+		}
+		return nil
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return &StatusError{Url: self.Url, StatusCode: resp.StatusCode, Status: resp.Status}
+	}
 	body, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
 		return err
 	}
-	out, err := unmarshalJson(body)
+	if format == nil {
+		format = detectFormat(resp.Header.Get("Content-Type"), self.Url)
+	}
+	out, err := format.Decode(body)
 	if err != nil {
 		return err
 	}
+	self.mu.Lock()
+	self.validated = self.Url
+	self.document = out
+	self.etag = resp.Header.Get("ETag")
+	self.lastModified = resp.Header.Get("Last-Modified")
+	self.mu.Unlock()
 	self.Reset(out)
 	return nil
 }
+
+// Starts polling the document at Url and reloads the config whenever it changes,
+// the document is fetched every interval. Call Stop on the returned Poller to stop polling.
+func (self *UrlConfig) Poll(interval time.Duration) *Poller {
+	poller := NewPoller(self, interval)
+	poller.Start()
+	return poller
+}
+
+// Lookup key from the underlaying Configurable
+func (self *UrlConfig) Lookup(key string) (string, bool) {
+	return LookupConfig(self.Configurable, key)
+}
+
+// Observe changes to the underlaying Configurable
+func (self *UrlConfig) Observe(fn func()) func() {
+	return ObserveConfig(self.Configurable, fn)
+}
+
+// Returns the url of the document for keys set in the config
+func (self *UrlConfig) Origin(key string) string {
+	if _, ok := self.Lookup(key); ok {
+		return self.Url
+	}
+	return ""
+}
@@ -1,7 +1,13 @@
 package gonfig_test
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
 	. "github.com/Nomon/gonfig"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -26,4 +32,62 @@ var _ = Describe("UrlConfig", func() {
 		Expect(err).ToNot(HaveOccurred())
 		Expect(cfg.Get("test")).To(Equal("abc"))
 	})
+
+	Describe("Requests", func() {
+		var (
+			server  *httptest.Server
+			request *http.Request
+			status  int
+		)
+		BeforeEach(func() {
+			status = http.StatusOK
+			server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				request = r
+				if r.URL.Path == "/slow" {
+					select {
+					case <-r.Context().Done():
+					case <-time.After(time.Second):
+					}
+				}
+				w.WriteHeader(status)
+				fmt.Fprint(w, `{"key":"value"}`)
+			}))
+		})
+		AfterEach(func() {
+			server.Close()
+		})
+		It("Should send headers and bearer tokens", func() {
+			url := &UrlConfig{Configurable: NewMemoryConfig(), Url: server.URL, Header: http.Header{"X-App": {"gonfig"}}, Token: "secret"}
+			Expect(url.Load()).To(Succeed())
+			Expect(url.Get("key")).To(Equal("value"))
+			Expect(request.Header.Get("X-App")).To(Equal("gonfig"))
+			Expect(request.Header.Get("Authorization")).To(Equal("Bearer secret"))
+		})
+		It("Should send basic auth with a custom client", func() {
+			url := &UrlConfig{Configurable: NewMemoryConfig(), Url: server.URL, Client: server.Client(), Username: "user", Password: "pass"}
+			Expect(url.Load()).To(Succeed())
+			username, password, ok := request.BasicAuth()
+			Expect(ok).To(BeTrue())
+			Expect(username).To(Equal("user"))
+			Expect(password).To(Equal("pass"))
+		})
+		It("Should fail on non-2xx responses and keep the loaded config", func() {
+			url := NewUrlConfig(server.URL)
+			Expect(url.Load()).To(Succeed())
+			status = http.StatusNotFound
+			err := url.Load()
+			var statusErr *StatusError
+			Expect(errors.As(err, &statusErr)).To(BeTrue())
+			Expect(statusErr.StatusCode).To(Equal(http.StatusNotFound))
+			Expect(err).To(MatchError("gonfig: GET " + server.URL + ": 404 Not Found"))
+			Expect(url.Get("key")).To(Equal("value"))
+		})
+		It("Should cancel loads with the context", func() {
+			url := NewUrlConfig(server.URL + "/slow").(*UrlConfig)
+			ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+			defer cancel()
+			err := url.LoadContext(ctx)
+			Expect(errors.Is(err, context.DeadlineExceeded)).To(BeTrue())
+		})
+	})
 })
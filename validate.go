@@ -0,0 +1,119 @@
+package gonfig
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// validates a decoded field value against the constraints in its tags:
+// min and max bound numbers and durations, and the length of strings, slices and maps,
+// oneof lists the space separated values allowed and regex is a pattern the value must match.
+// Elements of slices are checked against oneof and regex one by one.
+func validate(value reflect.Value, tag reflect.StructTag) error {
+	for value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			return nil
+		}
+		value = value.Elem()
+	}
+	if min, ok := tag.Lookup("min"); ok {
+		if err := checkBound(value, min, "min"); err != nil {
+			return err
+		}
+	}
+	if max, ok := tag.Lookup("max"); ok {
+		if err := checkBound(value, max, "max"); err != nil {
+			return err
+		}
+	}
+	elements := []reflect.Value{value}
+	if value.Kind() == reflect.Slice {
+		elements = elements[:0]
+		for i := 0; i < value.Len(); i++ {
+			elements = append(elements, reflect.Indirect(value.Index(i)))
+		}
+	}
+	if oneof, ok := tag.Lookup("oneof"); ok {
+		allowed := strings.Fields(oneof)
+		for _, element := range elements {
+			if !contains(allowed, formatValue(element)) {
+				return fmt.Errorf("must be one of %s", strings.Join(allowed, ", "))
+			}
+		}
+	}
+	if pattern, ok := tag.Lookup("regex"); ok {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return fmt.Errorf("invalid regex tag: %s", err)
+		}
+		for _, element := range elements {
+			if !re.MatchString(formatValue(element)) {
+				return fmt.Errorf("must match %s", pattern)
+			}
+		}
+	}
+	return nil
+}
+
+// checks value against the min or max bound
+func checkBound(value reflect.Value, bound, name string) error {
+	var actual, limit float64
+	var err error
+	// strings, slices and maps are bound by their length
+	subject := "must be"
+	switch {
+	case value.Type() == durationType:
+		var duration time.Duration
+		duration, err = time.ParseDuration(bound)
+		actual, limit = float64(value.Int()), float64(duration)
+	case value.Kind() >= reflect.Int && value.Kind() <= reflect.Int64:
+		actual = float64(value.Int())
+		limit, err = strconv.ParseFloat(bound, 64)
+	case value.Kind() >= reflect.Uint && value.Kind() <= reflect.Uintptr:
+		actual = float64(value.Uint())
+		limit, err = strconv.ParseFloat(bound, 64)
+	case value.Kind() == reflect.Float32 || value.Kind() == reflect.Float64:
+		actual = value.Float()
+		limit, err = strconv.ParseFloat(bound, 64)
+	case value.Kind() == reflect.String || value.Kind() == reflect.Slice || value.Kind() == reflect.Map:
+		actual = float64(value.Len())
+		limit, err = strconv.ParseFloat(bound, 64)
+		subject = "length must be"
+	default:
+		return fmt.Errorf("%s is not supported for %s", name, value.Type())
+	}
+	if err != nil {
+		return fmt.Errorf("invalid %s tag %q", name, bound)
+	}
+	if name == "min" && actual < limit {
+		return fmt.Errorf("%s at least %s", subject, bound)
+	}
+	if name == "max" && actual > limit {
+		return fmt.Errorf("%s at most %s", subject, bound)
+	}
+	return nil
+}
+
+// formats a decoded value for oneof and regex
+func formatValue(value reflect.Value) string {
+	if value.Type() == durationType {
+		return time.Duration(value.Int()).String()
+	}
+	if value.Kind() == reflect.String {
+		return value.String()
+	}
+	return fmt.Sprint(value.Interface())
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
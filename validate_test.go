@@ -0,0 +1,107 @@
+package gonfig_test
+
+import (
+	"errors"
+	"time"
+
+	. "github.com/Nomon/gonfig"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type validatedConfig struct {
+	Database struct {
+		Host string `gonfig:"host" required:"true"`
+		Port int    `gonfig:"port" default:"5432" required:"true" min:"1" max:"65535"`
+		User string `gonfig:"user" required:"true"`
+	} `gonfig:"db"`
+	Mode    string        `gonfig:"mode" default:"dev" oneof:"dev prod"`
+	Name    string        `gonfig:"name" regex:"^[a-z]+$" min:"2"`
+	Timeout time.Duration `gonfig:"timeout" default:"5s" max:"1m"`
+	Tags    []string      `gonfig:"tags" oneof:"a b c" max:"2"`
+	Retries *int          `gonfig:"retries" default:"3"`
+}
+
+var _ = Describe("Marshal validation", func() {
+	var (
+		cfg    *Gonfig
+		target validatedConfig
+	)
+	BeforeEach(func() {
+		cfg = NewConfig(nil)
+		target = validatedConfig{}
+		cfg.Use("memory", NewMemoryConfig()).Reset(map[string]string{
+			"db:host": "localhost",
+			"db:user": "app",
+			"name":    "gonfig",
+		})
+	})
+	It("Should apply defaults when no layer has the key", func() {
+		Expect(cfg.Marshal(&target)).To(Succeed())
+		Expect(target.Database.Port).To(Equal(5432))
+		Expect(target.Mode).To(Equal("dev"))
+		Expect(target.Timeout).To(Equal(5 * time.Second))
+		Expect(*target.Retries).To(Equal(3))
+	})
+	It("Should apply defaults and required to values that only have nested keys set", func() {
+		var flat struct {
+			DB   string `gonfig:"db" default:"fallback" required:"true" min:"3"`
+			Name string `gonfig:"name:first" required:"true"`
+			Port *int   `gonfig:"db:port" default:"1"`
+		}
+		cfg.Set("name:first:x", "nested")
+		cfg.Set("db:port:x", "nested")
+		err := cfg.Marshal(&flat)
+		Expect(flat.DB).To(Equal("fallback"))
+		Expect(*flat.Port).To(Equal(1))
+		var errs MarshalErrors
+		Expect(errors.As(err, &errs)).To(BeTrue())
+		Expect(errs.Missing()).To(Equal([]string{"name:first"}))
+	})
+	It("Should prefer the values of the layers over defaults", func() {
+		cfg.Defaults.Set("db:port", "6543")
+		cfg.Set("mode", "prod")
+		Expect(cfg.Marshal(&target)).To(Succeed())
+		Expect(target.Database.Port).To(Equal(6543))
+		Expect(target.Mode).To(Equal("prod"))
+	})
+	It("Should list all the missing required keys", func() {
+		cfg.Use("memory").Reset()
+		err := cfg.Marshal(&target)
+		Expect(err).To(HaveOccurred())
+		var errs MarshalErrors
+		Expect(errors.As(err, &errs)).To(BeTrue())
+		Expect(errs.Missing()).To(Equal([]string{"db:host", "db:user"}))
+		Expect(errors.Is(err, ErrRequired)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring(`key "db:host" (field Database.Host): required key is not set`))
+	})
+	It("Should report constraint violations per field", func() {
+		cfg.Set("db:port", "0")
+		cfg.Set("mode", "test")
+		cfg.Set("name", "Gonfig")
+		cfg.Set("timeout", "2m")
+		cfg.Set("tags", "a,d")
+		err := cfg.Marshal(&target)
+		var errs MarshalErrors
+		Expect(errors.As(err, &errs)).To(BeTrue())
+		Expect(errs).To(HaveLen(5))
+		Expect(errs[0].Error()).To(Equal(`key "db:port" (field Database.Port): invalid value "0": must be at least 1`))
+		Expect(errs[1].Error()).To(Equal(`key "mode" (field Mode): invalid value "test": must be one of dev, prod`))
+		Expect(errs[2].Error()).To(Equal(`key "name" (field Name): invalid value "Gonfig": must match ^[a-z]+$`))
+		Expect(errs[3].Error()).To(Equal(`key "timeout" (field Timeout): invalid value "2m": must be at most 1m`))
+		Expect(errs[4].Error()).To(Equal(`key "tags" (field Tags): invalid value "a,d": must be one of a, b, c`))
+	})
+	It("Should bound the length of strings and slices", func() {
+		cfg.Set("name", "a")
+		cfg.Set("tags", "a,b,c")
+		err := cfg.Marshal(&target)
+		Expect(err).To(MatchError(ContainSubstring("length must be at least 2")))
+		Expect(err).To(MatchError(ContainSubstring("length must be at most 2")))
+	})
+	It("Should report invalid defaults", func() {
+		var invalid struct {
+			Port int `gonfig:"port" default:"abc"`
+		}
+		Expect(cfg.Marshal(&invalid)).To(MatchError(ContainSubstring(`key "port" (field Port): invalid value "abc": invalid default`)))
+	})
+})
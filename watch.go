@@ -0,0 +1,132 @@
+package gonfig
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// The interval a Watcher polls the file at if no interval is given
+var DefaultWatchInterval = time.Second
+
+// Watcher polls a file and reloads a ReadableConfig whenever the file changes.
+// Changes are detected by the modification time, size and identity of the file,
+// so atomic replacements by rename and symlink swaps, like the ones done for
+// Kubernetes ConfigMap volumes, are detected too.
+// Sources only replace their data when the new file loads successfully, so a
+// broken or half written file leaves the previous configuration in place.
+type Watcher struct {
+	// Path of the watched file
+	Path string
+	// How often the file is checked for changes
+	Interval time.Duration
+	// Called after each reload with the error returned by Load, must be set before Start
+	OnReload func(error)
+
+	config ReadableConfig
+	// the error returned by the last reload
+	err     atomic.Pointer[error]
+	started atomic.Bool
+	once    sync.Once
+	stop    chan struct{}
+	done    chan struct{}
+}
+
+// Returns a new Watcher that reloads config when the file at path changes, the
+// watcher does not poll the file until Start is called.
+func NewWatcher(config ReadableConfig, path string, interval time.Duration) *Watcher {
+	if interval <= 0 {
+		interval = DefaultWatchInterval
+	}
+	return &Watcher{
+		Path:     path,
+		Interval: interval,
+		config:   config,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+}
+
+// Starts polling the file in a background goroutine, calling Start again does nothing
+func (self *Watcher) Start() {
+	if self.started.Swap(true) {
+		return
+	}
+	last := statFile(self.Path)
+	go func() {
+		defer close(self.done)
+		ticker := time.NewTicker(self.Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-self.stop:
+				return
+			case <-ticker.C:
+				current := statFile(self.Path)
+				if current.equal(last) {
+					continue
+				}
+				last = current
+				if current.info == nil {
+					// the file is gone, keep the loaded data until it reappears
+					continue
+				}
+				self.reload()
+			}
+		}
+	}()
+}
+
+// Stops watching the file and waits for a reload in progress to finish
+func (self *Watcher) Stop() {
+	self.once.Do(func() {
+		close(self.stop)
+	})
+	if self.started.Load() {
+		<-self.done
+	}
+}
+
+// Returns the error of the last reload, nil if it succeeded or no reload has happened
+func (self *Watcher) Err() error {
+	if err := self.err.Load(); err != nil {
+		return *err
+	}
+	return nil
+}
+
+func (self *Watcher) reload() {
+	err := self.config.Load()
+	self.err.Store(&err)
+	if self.OnReload != nil {
+		self.OnReload(err)
+	}
+}
+
+// the state of a watched file used to detect changes
+type fileState struct {
+	info os.FileInfo
+	// the path the file resolves to through symlinks
+	target string
+}
+
+func statFile(path string) fileState {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fileState{}
+	}
+	target, _ := filepath.EvalSymlinks(path)
+	return fileState{info, target}
+}
+
+func (self fileState) equal(other fileState) bool {
+	if self.info == nil || other.info == nil {
+		return self.info == other.info
+	}
+	return self.target == other.target &&
+		os.SameFile(self.info, other.info) &&
+		self.info.ModTime().Equal(other.info.ModTime()) &&
+		self.info.Size() == other.info.Size()
+}
@@ -0,0 +1,88 @@
+package gonfig_test
+
+import (
+	. "github.com/Nomon/gonfig"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+var _ = Describe("Watcher", func() {
+	const interval = 5 * time.Millisecond
+	var (
+		dir     string
+		path    string
+		cfg     WritableConfig
+		watcher *Watcher
+	)
+	write := func(path, content string) {
+		Expect(ioutil.WriteFile(path, []byte(content), 0600)).To(Succeed())
+	}
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "gonfig-watch")
+		Expect(err).ToNot(HaveOccurred())
+		path = filepath.Join(dir, "config.json")
+		watcher = nil
+	})
+	AfterEach(func() {
+		if watcher != nil {
+			watcher.Stop()
+		}
+		os.RemoveAll(dir)
+	})
+
+	It("Should reload the JsonConfig when the file changes", func() {
+		write(path, `{"key":"a"}`)
+		cfg = NewJsonConfig(path)
+		watcher = cfg.(*JsonConfig).Watch(interval)
+		write(path, `{"key":"changed"}`)
+		Eventually(func() string { return cfg.Get("key") }).Should(Equal("changed"))
+	})
+
+	It("Should keep the loaded data when the new file does not parse", func() {
+		write(path, `{"key":"a"}`)
+		cfg = NewJsonConfig(path)
+		reloads := make(chan error, 10)
+		watcher = NewWatcher(cfg, path, interval)
+		watcher.OnReload = func(err error) { reloads <- err }
+		watcher.Start()
+		write(path, `{"key":"bro`)
+		Eventually(reloads).Should(Receive(HaveOccurred()))
+		Expect(watcher.Err()).To(HaveOccurred())
+		Expect(cfg.Get("key")).To(Equal("a"))
+		write(path, `{"key":"fixed"}`)
+		Eventually(reloads).Should(Receive(BeNil()))
+		Expect(cfg.Get("key")).To(Equal("fixed"))
+	})
+
+	It("Should detect atomic symlink swaps", func() {
+		// mimics the layout of a Kubernetes ConfigMap volume
+		Expect(os.Mkdir(filepath.Join(dir, "v1"), 0700)).To(Succeed())
+		Expect(os.Mkdir(filepath.Join(dir, "v2"), 0700)).To(Succeed())
+		write(filepath.Join(dir, "v1", "config.json"), `{"key":"v1"}`)
+		write(filepath.Join(dir, "v2", "config.json"), `{"key":"v2"}`)
+		Expect(os.Symlink("v1", filepath.Join(dir, "..data"))).To(Succeed())
+		Expect(os.Symlink(filepath.Join("..data", "config.json"), path)).To(Succeed())
+		cfg = NewJsonConfig(path)
+		Expect(cfg.Get("key")).To(Equal("v1"))
+		watcher = cfg.(*JsonConfig).Watch(interval)
+
+		Expect(os.Symlink("v2", filepath.Join(dir, "..data_tmp"))).To(Succeed())
+		Expect(os.Rename(filepath.Join(dir, "..data_tmp"), filepath.Join(dir, "..data"))).To(Succeed())
+		Eventually(func() string { return cfg.Get("key") }).Should(Equal("v2"))
+	})
+
+	It("Should keep the data while the file is missing", func() {
+		write(path, `{"key":"a"}`)
+		cfg = NewJsonConfig(path)
+		watcher = cfg.(*JsonConfig).Watch(interval)
+		Expect(os.Remove(path)).To(Succeed())
+		Consistently(func() string { return cfg.Get("key") }, 50*time.Millisecond).Should(Equal("a"))
+		write(path, `{"key":"b"}`)
+		Eventually(func() string { return cfg.Get("key") }).Should(Equal("b"))
+	})
+})
@@ -0,0 +1,203 @@
+package gonfig
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// YamlConfig is a WritableConfig backed by a yaml file.
+// Nested mappings are flattened into ":" separated keys and sequences into
+// comma separated values the same way JsonConfig flattens objects and arrays,
+// so a sequence of mappings servers: [{name: a}] is found from "servers:0:name".
+// The types of the loaded values are remembered, so Save writes back the sequences, numbers,
+// booleans, nulls and timestamps.
+type YamlConfig struct {
+	FileConfig
+	// yaml types of the loaded keys, named like the json types
+	types MemoryConfig
+}
+
+// the type of loaded yaml timestamps, other yaml values have the json type of their kind
+const yamlTimestamp = "timestamp"
+
+// a yaml timestamp formatted as a string, dates without a time are formatted without one.
+// yamlTimes are flattened as strings and typed as timestamps by yamlTimestamps.
+type yamlTime string
+
+// Returns a new WritableConfig backed by a yaml file at path.
+// The file does not need to exist, if it does not exist the first Save call will create it.
+// Errors loading the file are not returned, use OpenYamlConfig or the error from Load
+// or Gonfig.Use to detect a missing or invalid file.
+func NewYamlConfig(path string, cfg ...Configurable) WritableConfig {
+	conf, _ := OpenYamlConfig(path, cfg...)
+	return conf
+}
+
+// Returns a new WritableConfig backed by a yaml file at path like NewYamlConfig,
+// along with the error from loading the file.
+func OpenYamlConfig(path string, cfg ...Configurable) (WritableConfig, error) {
+	conf := &YamlConfig{FileConfig: openFile(path, cfg)}
+	return conf, LoadConfig(conf)
+}
+
+// Attempts to load the yaml configuration at YamlConfig.Path
+// and Set them into the underlaying Configurable
+func (self *YamlConfig) Load() error {
+	data, err := ioutil.ReadFile(self.Path)
+	if err != nil {
+		return err
+	}
+	out, types, err := unmarshalYaml(data)
+	if err != nil {
+		return err
+	}
+	self.types.Reset(types)
+	self.Configurable.Reset(out)
+	return nil
+}
+
+// Attempts to save the configuration from the underlaying Configurable to the yaml file at YamlConfig.Path,
+// ":" separated keys are saved as nested mappings and loaded values keep their yaml types.
+// Values that were not loaded from the file are saved as strings.
+func (self *YamlConfig) Save() error {
+	types := self.types.All()
+	nested := nestKeys(self.Configurable.All(), func(key, value string) interface{} {
+		if typ, ok := types[key]; ok {
+			return yamlValue(value, typ)
+		}
+		return value
+	})
+	arraysFromIndexes(nested, types, jsonObjects)
+	b, err := yaml.Marshal(nested)
+	if err != nil {
+		return err
+	}
+	return writeFile(self.Path, b, 0)
+}
+
+// Reloads the config whenever the yaml file changes, see Watcher
+func (self *YamlConfig) Watch(interval time.Duration) *Watcher {
+	return watchFile(self, self.Path, interval)
+}
+
+// flattens a yaml document, returns the flattened values and their yaml types
+func unmarshalYaml(data []byte) (map[string]string, map[string]string, error) {
+	var document interface{}
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return nil, nil, err
+	}
+	output := make(map[string]string)
+	types := make(map[string]string)
+	if document == nil {
+		// an empty document
+		return output, types, nil
+	}
+	root, ok := normalizeYaml(document).(map[string]interface{})
+	if !ok {
+		return nil, nil, fmt.Errorf("yaml: document root is not a mapping")
+	}
+	unmarshalJsonSegment(root, "", output, types)
+	for key, value := range root {
+		yamlTimestamps(value, key, types)
+	}
+	return output, types, nil
+}
+
+// sets the type of the timestamps in value flattened to key, and of the sequences of timestamps,
+// to yamlTimestamp in the types set by unmarshalJsonSegment
+func yamlTimestamps(value interface{}, key string, types map[string]string) {
+	switch value := value.(type) {
+	case yamlTime:
+		types[key] = yamlTimestamp
+	case map[string]interface{}:
+		for k, v := range value {
+			yamlTimestamps(v, key+":"+k, types)
+		}
+	case []interface{}:
+		if indexed, ok := jsonIndexed(value); ok {
+			yamlTimestamps(indexed, key, types)
+			return
+		}
+		for _, v := range value {
+			if _, ok := v.(yamlTime); !ok {
+				return
+			}
+		}
+		if len(value) > 0 {
+			types[key] = jsonArray + yamlTimestamp
+		}
+	}
+}
+
+// converts the map[interface{}]interface{} mappings yaml can produce into map[string]interface{},
+// numbers into json.Numbers and timestamps into yamlTimes so they are flattened with their types
+func normalizeYaml(value interface{}) interface{} {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		for k, v := range value {
+			value[k] = normalizeYaml(v)
+		}
+		return value
+	case map[interface{}]interface{}:
+		normalized := make(map[string]interface{}, len(value))
+		for k, v := range value {
+			normalized[fmt.Sprint(k)] = normalizeYaml(v)
+		}
+		return normalized
+	case []interface{}:
+		for i, v := range value {
+			value[i] = normalizeYaml(v)
+		}
+		return value
+	case int, int64, uint64, float64:
+		return json.Number(fmt.Sprint(value))
+	case time.Time:
+		if value.Equal(value.Truncate(24*time.Hour)) && value.Location() == time.UTC {
+			return yamlTime(value.Format(time.DateOnly))
+		}
+		return yamlTime(value.Format(time.RFC3339Nano))
+	}
+	return value
+}
+
+// converts a flattened value back to a value of the yaml type typ, the elements of mixed sequences
+// are inferred. Values that do not parse as typ are kept as strings.
+func yamlValue(value, typ string) interface{} {
+	if strings.HasPrefix(typ, jsonArray) {
+		values := []interface{}{}
+		if value == "" {
+			return values
+		}
+		for _, v := range trimsplit(value, ",") {
+			values = append(values, yamlValue(v, strings.TrimPrefix(typ, jsonArray)))
+		}
+		return values
+	}
+	switch typ {
+	case jsonNumber:
+		if i, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return i
+		}
+		if f, err := strconv.ParseFloat(value, 64); err == nil {
+			return f
+		}
+	case yamlTimestamp:
+		for _, layout := range []string{time.DateOnly, time.RFC3339Nano} {
+			if _, err := time.Parse(layout, value); err == nil {
+				return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!timestamp", Value: value}
+			}
+		}
+	case "":
+		// an element of a mixed sequence
+		if jsonNumberPattern.MatchString(value) {
+			return yamlValue(value, jsonNumber)
+		}
+	}
+	return jsonValue(value, typ, typ == "")
+}
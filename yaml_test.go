@@ -0,0 +1,126 @@
+package gonfig_test
+
+import (
+	. "github.com/Nomon/gonfig"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"io/ioutil"
+	"os"
+	"strings"
+)
+
+var _ = Describe("YamlConfig", func() {
+	var (
+		err error
+		cfg WritableConfig
+	)
+
+	BeforeEach(func() {
+		cfg, err = OpenYamlConfig("./config_valid.yaml")
+	})
+
+	Context("When the YAML config loads properly", func() {
+		It("Should not error", func() {
+			Expect(err).NotTo(HaveOccurred())
+		})
+		It("Should have the scalar values", func() {
+			Expect(cfg.Get("test")).To(Equal("123"))
+			Expect(cfg.Get("test_number")).To(Equal("1"))
+			Expect(cfg.Get("test_bool")).To(Equal("true"))
+			Expect(cfg.Get("test_float")).To(Equal("12.34"))
+		})
+		It("Should flatten nested mappings like JsonConfig", func() {
+			Expect(cfg.Get("test_object:nested_string")).To(Equal("abcd"))
+			Expect(cfg.Get("test_object:nested_int")).To(Equal("987"))
+			Expect(cfg.Get("double_nested:nested_object:test_inner")).To(Equal("foo"))
+			Expect(cfg.All()).To(Equal(NewJsonConfig("./config_valid.json").All()))
+		})
+		It("Should join sequences like JsonConfig", func() {
+			Expect(cfg.Get("test_array")).To(Equal("1,2,3"))
+		})
+	})
+
+	Context("When the YAML config fails to load", func() {
+		It("should error for invalid yaml", func() {
+			cfg, err = OpenYamlConfig("./config_invalid.yaml")
+			Expect(err).To(HaveOccurred())
+			cfg.Set("QQ", "123")
+			Expect(cfg.Get("QQ")).To(Equal("123"))
+		})
+		It("should error for a missing file", func() {
+			cfg, err = OpenYamlConfig("./config_nonexisting.yaml")
+			Expect(os.IsNotExist(err)).To(BeTrue())
+		})
+	})
+
+	Describe("Save", func() {
+		path := "./config_test.yaml"
+		AfterEach(func() {
+			os.Remove(path)
+		})
+		It("Should save nested yaml that loads back to the same values", func() {
+			saved := NewYamlConfig(path, NewMemoryConfig())
+			saved.Reset(cfg.All())
+			Expect(saved.Save()).To(Succeed())
+			loaded, err := OpenYamlConfig(path)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(loaded.All()).To(Equal(cfg.All()))
+		})
+		It("Should save mappings and sequences back as nested yaml", func() {
+			Expect(ioutil.WriteFile(path, []byte("db:\n  hosts: [a, b]\n  port: \"5432\"\n"), 0600)).To(Succeed())
+			saved := NewYamlConfig(path)
+			saved.Set("db:name", "app")
+			Expect(saved.Save()).To(Succeed())
+			data, err := ioutil.ReadFile(path)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(data)).To(Equal("db:\n    hosts:\n        - a\n        - b\n    name: app\n    port: \"5432\"\n"))
+		})
+		It("Should index sequences of mappings and save them back as sequences", func() {
+			Expect(ioutil.WriteFile(path, []byte("servers:\n    - name: a\n    - name: b\n      port: 80\n"), 0600)).To(Succeed())
+			saved := NewYamlConfig(path)
+			Expect(saved.Get("servers:1:port")).To(Equal("80"))
+			Expect(saved.Save()).To(Succeed())
+			data, err := ioutil.ReadFile(path)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(data)).To(Equal("servers:\n    - name: a\n    - name: b\n      port: 80\n"))
+		})
+		It("Should save scalars back with their yaml types", func() {
+			document := strings.Join([]string{
+				"day: 2001-12-14",
+				"flag: true",
+				"matrix:",
+				"    - - 1",
+				"      - 2",
+				"    - []",
+				"mixed:",
+				"    - 1",
+				"    - a",
+				"    - false",
+				"none: null",
+				"port: 80",
+				"ratio: 0.5",
+				"text: \"80\"",
+				"time: 2001-12-14T21:59:43.1-05:00",
+				"times:",
+				"    - 2001-12-14",
+				"    - - 2001-12-15",
+				"",
+			}, "\n")
+			Expect(ioutil.WriteFile(path, []byte(document), 0600)).To(Succeed())
+			saved := NewYamlConfig(path)
+			Expect(saved.Get("day")).To(Equal("2001-12-14"))
+			Expect(saved.Get("time")).To(Equal("2001-12-14T21:59:43.1-05:00"))
+			Expect(saved.Get("matrix:0")).To(Equal("1,2"))
+			Expect(saved.Save()).To(Succeed())
+			data, err := ioutil.ReadFile(path)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(data)).To(Equal(document))
+		})
+		It("Should keep keys that conflict with nested values flat", func() {
+			saved := NewYamlConfig(path)
+			saved.Reset(map[string]string{"a": "1", "a:b": "2"})
+			Expect(saved.Save()).To(Succeed())
+			Expect(NewYamlConfig(path).All()).To(Equal(map[string]string{"a": "1", "a:b": "2"}))
+		})
+	})
+})